@@ -4,6 +4,13 @@
 
 package art
 
+import (
+	"errors"
+	"iter"
+	"math/rand"
+	"regexp"
+)
+
 // Kind - adaptive radix tree node type.
 type Kind uint8
 
@@ -19,29 +26,202 @@ const (
 // Key type. Can be any sequence of characters.
 type Key = []byte
 
-// Value type.
+// Value type of the tree created by New.
 type Value = interface{}
 
-// Node - delineate node entity.
-type Node interface {
+// Node - delineate node entity that holds a value of type V.
+type Node[V any] interface {
 	Kind() Kind
 	Key() Key
-	Value() Value
+	Value() V
 }
 
 // Callback - callback function that is passed in Each.
-type Callback func(node Node)
-
-// Tree - delineate adaptive radix tree entity.
-type Tree interface {
-	Insert(key Key, value Value)
-	Search(key Key) (value Value)
-	Delete(key Key) (deleted bool)
-	Each(cb Callback, options ...int)
+type Callback[V any] func(node Node[V])
+
+// WalkFunc - callback function that is passed in Walk.
+// It may return SkipNode to skip the children of the node,
+// or SkipAll or any other error to stop the walk.
+type WalkFunc[V any] func(node Node[V]) error
+
+// Action - tells Update what to do with the key once the update function returns.
+type Action int
+
+const (
+	// UpdateKeep - leaves the tree untouched.
+	UpdateKeep Action = iota
+	// UpdateStore - stores the returned value by the key, inserting the key if it's absent.
+	UpdateStore
+	// UpdateDelete - removes the key if it's present.
+	UpdateDelete
+)
+
+// UpdateFunc - function that is passed in Update. It receives the value stored by the key
+// and whether the key is present, and returns the new value along with the action to take.
+type UpdateFunc[V any] func(old V, exists bool) (V, Action)
+
+var (
+	// SkipNode - used as a return value from WalkFunc to skip the children of the node.
+	SkipNode = errors.New("skip the children of this node")
+	// SkipAll - used as a return value from WalkFunc to stop the walk.
+	SkipAll = errors.New("skip everything and stop the walk")
+	// ErrBadPattern - returned by MatchGlob when the pattern is malformed.
+	ErrBadPattern = errors.New("syntax error in pattern")
+)
+
+// Traversal options. Options can be combined by bitwise OR.
+// Each and Walk visit all kinds of nodes in pre-order by default,
+// while the other traversals visit leaves only.
+const (
+	// RangeExcludeStart - excludes the start key from the range.
+	RangeExcludeStart = 1 << iota
+	// RangeIncludeEnd - includes the end key into the range.
+	RangeIncludeEnd
+	// TraverseReverse - iterates in descending order of keys.
+	TraverseReverse
+	// TraverseLeaf - visits leaves.
+	TraverseLeaf
+	// TraverseNode4 - visits inner nodes of type Node4.
+	TraverseNode4
+	// TraverseNode16 - visits inner nodes of type Node16.
+	TraverseNode16
+	// TraverseNode48 - visits inner nodes of type Node48.
+	TraverseNode48
+	// TraverseNode256 - visits inner nodes of type Node256.
+	TraverseNode256
+	// TraversePostOrder - visits inner nodes after their children instead of before.
+	TraversePostOrder
+	// RegexpPOSIX - tells MatchRegexp that the expression is compiled with regexp.CompilePOSIX.
+	RegexpPOSIX
+
+	// TraverseNode - visits inner nodes of all types.
+	TraverseNode = TraverseNode4 | TraverseNode16 | TraverseNode48 | TraverseNode256
+	// TraverseAll - visits all nodes. It's the default unless some kinds of nodes are chosen.
+	TraverseAll = TraverseLeaf | TraverseNode
+)
+
+// Tree - delineate adaptive radix tree entity that stores values of type V.
+// Any byte sequence is a valid key, including the empty key, which sorts before all other keys.
+// A nil key passed in as a single key is the same key as the empty one,
+// while a nil bound of a range is open, see Range.
+type Tree[V any] interface {
+	// Insert - inserts the value by the key, replacing the value of an existing key.
+	// Returns the previous value and true if the key was already present.
+	Insert(key Key, value V) (oldValue V, updated bool)
+	// InsertIfAbsent - inserts the value only if the key is not present yet.
+	// Returns the stored value and false if the key was already present.
+	InsertIfAbsent(key Key, value V) (oldValue V, inserted bool)
+	// Update - calls fn with the current value of the key and stores or removes the key
+	// according to the returned action, all in a single descent.
+	// Returns the value stored by the key afterwards and whether the key is present.
+	Update(key Key, fn UpdateFunc[V]) (value V, present bool)
+	// GetOrInsert - returns the value stored by the key and true if the key is present,
+	// otherwise inserts the value and returns it along with false.
+	GetOrInsert(key Key, value V) (actual V, loaded bool)
+	// CompareAndSwap - stores the new value by the key if the stored value is equal to the old one.
+	// A nil equal compares the values with ==, which panics if they aren't comparable.
+	CompareAndSwap(key Key, old, new V, equal func(a, b V) bool) (swapped bool)
+	// CompareAndDelete - removes the key if the stored value is equal to the old one.
+	// A nil equal compares the values with ==, which panics if they aren't comparable.
+	CompareAndDelete(key Key, old V, equal func(a, b V) bool) (deleted bool)
+	Search(key Key) (value V)
+	// Lookup - returns the value stored by the key and whether the key is present,
+	// which allows to distinguish a missing key from a stored zero value.
+	Lookup(key Key) (value V, found bool)
+	// LongestPrefix - returns the longest stored key that is a prefix of the key along with its value,
+	// ok is false if there is no such key.
+	LongestPrefix(key Key) (prefix Key, value V, ok bool)
+	// EachPrefixOf - calls cb for every stored key that is a prefix of the key,
+	// from the shortest to the longest one.
+	EachPrefixOf(key Key, cb Callback[V])
+	// Delete - removes the key. Returns the removed value and whether the key was present.
+	Delete(key Key) (value V, deleted bool)
+	// DeletePrefix - removes every key that starts with the prefix at once.
+	// Returns the number of removed keys.
+	DeletePrefix(prefix Key) int
+	// DeleteRange - removes every key within [start, end) at once.
+	// The bounds are treated the same way as in Range. Returns the number of removed keys.
+	// Note that a nil bound is open, so a nil end removes every key from start onward,
+	// use an empty non-nil bound for the empty key.
+	DeleteRange(start, end Key, options ...int) int
+	// Minimum - returns the smallest key and its value, ok is false if the tree is empty.
+	Minimum() (key Key, value V, ok bool)
+	// Maximum - returns the largest key and its value, ok is false if the tree is empty.
+	Maximum() (key Key, value V, ok bool)
+	// Floor - returns the greatest key that is less than or equal to the key and its value.
+	Floor(key Key) (floor Key, value V, ok bool)
+	// Ceiling - returns the smallest key that is greater than or equal to the key and its value.
+	Ceiling(key Key) (ceiling Key, value V, ok bool)
+	// Predecessor - returns the greatest key that is less than the key and its value.
+	Predecessor(key Key) (predecessor Key, value V, ok bool)
+	// Successor - returns the smallest key that is greater than the key and its value.
+	Successor(key Key) (successor Key, value V, ok bool)
+	// Rank - returns the number of keys that are less than the key.
+	Rank(key Key) int
+	// Select - returns the key at the index in key order and its value,
+	// ok is false if the index is out of range.
+	Select(index int) (key Key, value V, ok bool)
+	// RandomKey - returns a uniformly chosen random key and its value using rng,
+	// ok is false if the tree is empty.
+	RandomKey(rng *rand.Rand) (key Key, value V, ok bool)
+	// RandomKeyWithPrefix - returns a uniformly chosen random key that starts with the prefix
+	// and its value using rng, ok is false if there are no such keys.
+	RandomKeyWithPrefix(prefix Key, rng *rand.Rand) (key Key, value V, ok bool)
+	// Count - returns the number of keys within [start, end).
+	// The bounds are treated the same way as in Range.
+	Count(start, end Key, options ...int) int
+	// CountPrefix - returns the number of keys that start with the prefix.
+	CountPrefix(prefix Key) int
+	// PopMin - removes and returns the smallest key and its value.
+	PopMin() (key Key, value V, ok bool)
+	// PopMax - removes and returns the largest key and its value.
+	PopMax() (key Key, value V, ok bool)
+	// Each - calls cb for the nodes of the tree in pre-order,
+	// traversal options choose the kinds of visited nodes and the order.
+	Each(cb Callback[V], options ...int)
+	// Walk - iterates over the nodes the same way as Each, but lets cb skip subtrees
+	// by returning SkipNode or stop by returning SkipAll. Other errors stop the walk and are returned.
+	Walk(cb WalkFunc[V], options ...int) error
+	// EachPrefix - calls cb for every leaf whose key starts with the prefix, in key order.
+	EachPrefix(prefix Key, cb Callback[V], options ...int)
+	// Range - calls cb for every leaf with a key within [start, end) in key order.
+	// A nil bound is open, while an empty non-nil bound is the empty key.
+	// RangeExcludeStart and RangeIncludeEnd options change the bounds.
+	Range(start, end Key, cb Callback[V], options ...int)
+	// FuzzySearch - calls cb for every leaf whose key is within the Levenshtein distance
+	// of the query, in key order.
+	FuzzySearch(query Key, maxDistance int, cb Callback[V])
+	// MatchGlob - calls cb for every leaf whose key matches the glob pattern, in key order.
+	// Returns ErrBadPattern if the pattern is malformed.
+	MatchGlob(pattern string, cb Callback[V]) error
+	// MatchRegexp - calls cb for every leaf whose key matches the regular expression, in key order.
+	// A key matches the same way as re.Match does. The expression is expected to be compiled
+	// with regexp.Compile, pass RegexpPOSIX if it's compiled with regexp.CompilePOSIX.
+	MatchRegexp(re *regexp.Regexp, cb Callback[V], options ...int)
+	// WithinHamming - calls cb for every leaf whose key has the same length as the query
+	// and differs from it in at most maxBits bits, in key order.
+	WithinHamming(query Key, maxBits int, cb Callback[V])
+	// Iterator - returns a new pull-style iterator over the keys of the tree.
+	Iterator() Iterator[V]
+	// All - returns an iterator over all keys and values in key order.
+	All() iter.Seq2[Key, V]
+	// Backward - returns an iterator over all keys and values in descending key order.
+	Backward() iter.Seq2[Key, V]
+	// ScanPrefix - returns an iterator over the keys starting with the prefix and their values.
+	ScanPrefix(prefix Key, options ...int) iter.Seq2[Key, V]
+	// ScanRange - returns an iterator over the keys within [start, end) and their values.
+	// The bounds are treated the same way as in Range.
+	ScanRange(start, end Key, options ...int) iter.Seq2[Key, V]
 	Size() int
 }
 
-// New - creates a new instace of adaptive radix tree.
-func New() Tree {
-	return newArt()
+// New - creates a new instace of adaptive radix tree that stores values of any type.
+func New() Tree[Value] {
+	return newArt[Value]()
+}
+
+// NewTree - creates a new instance of adaptive radix tree that stores values of type V.
+// Values are stored in leaves as is, without boxing them into interfaces.
+func NewTree[V any]() Tree[V] {
+	return newArt[V]()
 }
@@ -0,0 +1,71 @@
+// Copyright © 2019, Oleksandr Krykovliuk <k33nice@gmail.com>.
+// Use of this source code is governed by the
+// MIT license that can be found in the LICENSE file.
+
+package art
+
+// Matches the paths against the query with the rows of the edit distance matrix.
+// A row holds the distances between the path and every prefix of the query.
+type fuzzyMatcher struct {
+	query       []byte
+	maxDistance int
+}
+
+// Calls the passed in callback for every leaf whose key is within the passed in
+// Levenshtein distance of the query, in key order.
+func (t *tree[V]) FuzzySearch(query Key, maxDistance int, callback Callback[V]) {
+	if maxDistance < 0 {
+		return
+	}
+
+	// The distances between the empty path and every prefix of the query.
+	row := make([]int, len(query)+1)
+	for j := range row {
+		row[j] = j
+	}
+
+	matchHelper[V, []int](t, t.root, &fuzzyMatcher{query: query, maxDistance: maxDistance}, 0, row, callback)
+}
+
+// A subtree is pruned once every distance of the row exceeds the bound,
+// since appending bytes to the path never decreases the minimum of the row.
+func (m *fuzzyMatcher) step(row []int, _ int, b byte) ([]int, bool) {
+	row, least := levenshteinRow(row, m.query, b)
+	return row, least <= m.maxDistance
+}
+
+// The last distance of the row is the distance between the key and the whole query.
+func (m *fuzzyMatcher) accepts(row []int, _ Key) bool {
+	return row[len(m.query)] <= m.maxDistance
+}
+
+func (m *fuzzyMatcher) acceptsRest([]int) bool {
+	return false
+}
+
+func (m *fuzzyMatcher) literals([]int, int) ([]byte, bool) {
+	return nil, false
+}
+
+// Computes the next row of the edit distance matrix after appending the passed in byte to the path.
+// Returns the new row along with its minimum.
+func levenshteinRow(prev []int, query []byte, b byte) ([]int, int) {
+	row := make([]int, len(prev))
+	row[0] = prev[0] + 1
+	least := row[0]
+
+	for j := 1; j < len(row); j++ {
+		cost := 1
+		if query[j-1] == b {
+			cost = 0
+		}
+
+		// Deletion, insertion and substitution respectively.
+		row[j] = min(min(prev[j]+1, row[j-1]+1), prev[j-1]+cost)
+		if row[j] < least {
+			least = row[j]
+		}
+	}
+
+	return row, least
+}
@@ -0,0 +1,77 @@
+// Copyright © 2019, Oleksandr Krykovliuk <k33nice@gmail.com>.
+// Use of this source code is governed by the
+// MIT license that can be found in the LICENSE file.
+
+package art
+
+import (
+	"testing"
+
+	"github.com/k33nice/libart/internal/test"
+	"github.com/stretchr/testify/assert"
+)
+
+// Computes the Levenshtein distance between two keys with the full matrix.
+func levenshtein(a, b []byte) int {
+	row := make([]int, len(b)+1)
+	for j := range row {
+		row[j] = j
+	}
+	for _, c := range a {
+		row, _ = levenshteinRow(row, b, c)
+	}
+	return row[len(b)]
+}
+
+func fuzzyKeys(tree *tree[Value], query string, maxDistance int) []string {
+	return searchKeys(func(callback Callback[Value]) {
+		tree.FuzzySearch(Key(query), maxDistance, callback)
+	})
+}
+
+// FuzzySearch should find keys within the distance, including prefixes and extensions of the query.
+func TestFuzzySearch(t *testing.T) {
+	tree := newArt[Value]()
+	for _, key := range []string{"", "a", "cat", "cats", "cart", "cut", "dog", "scat", "category", "cathedral"} {
+		tree.Insert(Key(key), key)
+	}
+
+	assert.Equal(t, []string{"cat"}, fuzzyKeys(tree, "cat", 0))
+	assert.Equal(t, []string{"cart", "cat", "cats", "cut", "scat"}, fuzzyKeys(tree, "cat", 1))
+	assert.Equal(t, []string{"", "a"}, fuzzyKeys(tree, "", 1))
+	assert.Equal(t, []string{"category"}, fuzzyKeys(tree, "catgeory", 2))
+	assert.Empty(t, fuzzyKeys(tree, "cat", -1))
+	assert.Empty(t, fuzzyKeys(newArt[Value](), "cat", 3))
+}
+
+// FuzzySearch over the words should match a brute force comparison with every word.
+func TestFuzzySearchManyWords(t *testing.T) {
+	tree := newArt[Value]()
+	words := test.LoadTestFile("test/data/words.txt")
+	for _, w := range words {
+		tree.Insert(w, w)
+	}
+
+	var sorted []Key
+	tree.Each(func(node Node[Value]) {
+		sorted = append(sorted, node.Key())
+	}, TraverseLeaf)
+
+	for _, query := range []string{"algorithm", "radix", "tre", "abandonment"} {
+		distances := make([]int, len(sorted))
+		for i, key := range sorted {
+			distances[i] = levenshtein(key, Key(query))
+		}
+
+		for maxDistance := 0; maxDistance <= 2; maxDistance++ {
+			var expected []string
+			for i, key := range sorted {
+				if distances[i] <= maxDistance {
+					expected = append(expected, string(key))
+				}
+			}
+
+			assert.Equal(t, expected, fuzzyKeys(tree, query, maxDistance), "%s within %d", query, maxDistance)
+		}
+	}
+}
@@ -0,0 +1,239 @@
+// Copyright © 2019, Oleksandr Krykovliuk <k33nice@gmail.com>.
+// Use of this source code is governed by the
+// MIT license that can be found in the LICENSE file.
+
+package art
+
+import "slices"
+
+// Kinds of glob pattern elements.
+const (
+	globLiteral = iota
+	globAny
+	globStar
+	globClass
+)
+
+// A single element of a glob pattern that matches one byte, or any number of bytes for a star.
+type globToken struct {
+	kind    int
+	literal byte
+	class   [4]uint64
+}
+
+// Reports whether the token consumes the passed in byte.
+func (tok *globToken) matches(b byte) bool {
+	switch tok.kind {
+	case globLiteral:
+		return tok.literal == b
+	case globClass:
+		return tok.class[b>>6]&(1<<(b&63)) != 0
+	}
+	return true
+}
+
+// A compiled glob pattern, which is matched as an NFA whose states are the positions in the tokens.
+type globPattern struct {
+	tokens []globToken
+	// Every token starting from tail is a star, so reaching it accepts any rest of the key
+	// unless the pattern doesn't end with a star.
+	tail int
+}
+
+// Calls the passed in callback for every leaf whose key matches the glob pattern, in key order.
+// The pattern matches whole keys byte by byte:
+//
+//	'?'         matches any single byte
+//	'*'         matches any sequence of bytes, including the empty one
+//	'[abc]'     matches any of the listed bytes, ranges like 'a-z' are allowed
+//	'[!abc]'    matches any byte that isn't listed, '[^abc]' is the same
+//	'\c'        matches the byte c literally
+//
+// Only the branches that can still lead to a match are traversed.
+// Returns ErrBadPattern if the pattern is malformed.
+func (t *tree[V]) MatchGlob(pattern string, callback Callback[V]) error {
+	glob, err := compileGlob(pattern)
+	if err != nil {
+		return err
+	}
+
+	matchHelper[V, []int](t, t.root, glob, 0, glob.closure([]int{0}), callback)
+	return nil
+}
+
+// Parses the passed in glob pattern into tokens.
+func compileGlob(pattern string) (*globPattern, error) {
+	var tokens []globToken
+
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '?':
+			tokens = append(tokens, globToken{kind: globAny})
+
+		case '*':
+			// Consecutive stars are the same as a single one.
+			if len(tokens) == 0 || tokens[len(tokens)-1].kind != globStar {
+				tokens = append(tokens, globToken{kind: globStar})
+			}
+
+		case '\\':
+			i++
+			if i >= len(pattern) {
+				return nil, ErrBadPattern
+			}
+			tokens = append(tokens, globToken{kind: globLiteral, literal: pattern[i]})
+
+		case '[':
+			tok, end, err := compileGlobClass(pattern, i+1)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, tok)
+			i = end
+
+		default:
+			tokens = append(tokens, globToken{kind: globLiteral, literal: c})
+		}
+	}
+
+	tail := len(tokens)
+	for tail > 0 && tokens[tail-1].kind == globStar {
+		tail--
+	}
+
+	return &globPattern{tokens: tokens, tail: tail}, nil
+}
+
+// Parses a character class starting right after its opening bracket.
+// Returns the class token along with the index of its closing bracket.
+func compileGlobClass(pattern string, i int) (globToken, int, error) {
+	tok := globToken{kind: globClass}
+
+	negate := i < len(pattern) && (pattern[i] == '!' || pattern[i] == '^')
+	if negate {
+		i++
+	}
+
+	for empty := true; ; empty = false {
+		if i >= len(pattern) {
+			return tok, 0, ErrBadPattern
+		}
+		if pattern[i] == ']' {
+			if empty {
+				return tok, 0, ErrBadPattern
+			}
+			break
+		}
+
+		lo, next, err := globClassByte(pattern, i)
+		if err != nil {
+			return tok, 0, err
+		}
+		hi := lo
+		i = next
+
+		if i+1 < len(pattern) && pattern[i] == '-' && pattern[i+1] != ']' {
+			if hi, i, err = globClassByte(pattern, i+1); err != nil {
+				return tok, 0, err
+			}
+			if hi < lo {
+				return tok, 0, ErrBadPattern
+			}
+		}
+
+		for b := int(lo); b <= int(hi); b++ {
+			tok.class[b>>6] |= 1 << (b & 63)
+		}
+	}
+
+	if negate {
+		for k := range tok.class {
+			tok.class[k] = ^tok.class[k]
+		}
+	}
+
+	return tok, i, nil
+}
+
+// Returns the possibly escaped byte of a character class at the passed in index
+// along with the index that follows it.
+func globClassByte(pattern string, i int) (byte, int, error) {
+	if pattern[i] == '\\' {
+		i++
+		if i >= len(pattern) {
+			return 0, 0, ErrBadPattern
+		}
+	}
+	return pattern[i], i + 1, nil
+}
+
+// Adds the states reachable without consuming a byte, which is skipping stars.
+// The passed in states must be sorted, and so are the returned ones.
+func (g *globPattern) closure(states []int) []int {
+	var closed []int
+	for _, state := range states {
+		for ; ; state++ {
+			if n := len(closed); n == 0 || closed[n-1] < state {
+				closed = append(closed, state)
+			}
+			if state == len(g.tokens) || g.tokens[state].kind != globStar {
+				break
+			}
+		}
+	}
+	return closed
+}
+
+// Returns the states reachable from the passed in ones by consuming the byte,
+// and false if none of them is left.
+func (g *globPattern) step(states []int, _ int, b byte) ([]int, bool) {
+	var next []int
+	for _, state := range states {
+		if state == len(g.tokens) {
+			continue
+		}
+
+		tok := &g.tokens[state]
+		if !tok.matches(b) {
+			continue
+		}
+
+		// A star consumes the byte and stays in the same state.
+		if tok.kind != globStar {
+			state++
+		}
+		if n := len(next); n == 0 || next[n-1] < state {
+			next = append(next, state)
+		}
+	}
+	next = g.closure(next)
+	return next, len(next) != 0
+}
+
+// Reports whether the passed in states accept the key.
+func (g *globPattern) accepts(states []int, _ Key) bool {
+	return len(states) != 0 && states[len(states)-1] == len(g.tokens)
+}
+
+// Reports whether the passed in states accept any continuation of the path.
+func (g *globPattern) acceptsRest(states []int) bool {
+	return g.tail < len(g.tokens) && len(states) != 0 && states[len(states)-1] >= g.tail
+}
+
+// Returns the sorted bytes that can be consumed by the passed in states,
+// or false if any of the states consumes more than a single literal byte.
+func (g *globPattern) literals(states []int, _ int) ([]byte, bool) {
+	var literals []byte
+	for _, state := range states {
+		if state == len(g.tokens) {
+			continue
+		}
+		if g.tokens[state].kind != globLiteral {
+			return nil, false
+		}
+		literals = append(literals, g.tokens[state].literal)
+	}
+
+	slices.Sort(literals)
+	return slices.Compact(literals), true
+}
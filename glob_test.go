@@ -0,0 +1,76 @@
+// Copyright © 2019, Oleksandr Krykovliuk <k33nice@gmail.com>.
+// Use of this source code is governed by the
+// MIT license that can be found in the LICENSE file.
+
+package art
+
+import (
+	"path"
+	"testing"
+
+	"github.com/k33nice/libart/internal/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func globKeys(t *testing.T, tree *tree[Value], pattern string) []string {
+	return searchKeys(func(callback Callback[Value]) {
+		assert.NoError(t, tree.MatchGlob(pattern, callback), pattern)
+	})
+}
+
+// MatchGlob should support wildcards, character classes and escapes.
+func TestMatchGlob(t *testing.T) {
+	tree := newArt[Value]()
+	keys := []string{
+		"", "user:1:session", "user:1:profile", "user:12:session", "user:2:session",
+		"user::session", "user:1:session:old", "users", "a*b", "a?b", "axb", "a\x00b",
+	}
+	for _, key := range keys {
+		tree.Insert(Key(key), key)
+	}
+
+	assert.Equal(t, []string{"user:12:session", "user:1:session", "user:2:session", "user::session"}, globKeys(t, tree, "user:*:session"))
+	assert.Equal(t, []string{"user:1:session", "user:2:session"}, globKeys(t, tree, "user:?:session"))
+	assert.Equal(t, []string{"user:12:session", "user:1:session"}, globKeys(t, tree, "user:1*:session"))
+	assert.Equal(t, []string{"user:2:session"}, globKeys(t, tree, "user:[!1]:session"))
+	assert.Equal(t, []string{"user:2:session"}, globKeys(t, tree, "user:[^0-1]:*n"))
+	assert.Equal(t, []string{"user:1:profile", "user:1:session", "user:1:session:old"}, globKeys(t, tree, "user:[1]:*"))
+	assert.Equal(t, []string{"a\x00b", "a*b", "a?b", "axb"}, globKeys(t, tree, "a?b"))
+	assert.Equal(t, []string{"a*b"}, globKeys(t, tree, "a\\*b"))
+	assert.Equal(t, []string{"a*b", "a?b"}, globKeys(t, tree, "a[*?]b"))
+	assert.Equal(t, []string{"a?b"}, globKeys(t, tree, "a[\\?]b"))
+	assert.Equal(t, []string{"users"}, globKeys(t, tree, "users"))
+	assert.Equal(t, []string{""}, globKeys(t, tree, ""))
+	assert.Len(t, globKeys(t, tree, "*"), len(keys))
+	assert.Empty(t, globKeys(t, tree, "user"))
+	assert.Empty(t, globKeys(t, newArt[Value](), "*"))
+
+	for _, pattern := range []string{"[", "[]", "[!]", "a[bc", "[z-a]", "abc\\", "[a\\"} {
+		assert.Equal(t, ErrBadPattern, tree.MatchGlob(pattern, func(Node[Value]) {}), pattern)
+	}
+}
+
+// MatchGlob over the words should agree with path.Match, which has the same syntax for them
+// except for the negated classes that path.Match only writes as [^...].
+func TestMatchGlobManyWords(t *testing.T) {
+	tree := newArt[Value]()
+	words := test.LoadTestFile("test/data/words.txt")
+	for _, w := range words {
+		tree.Insert(w, w)
+	}
+
+	var sorted []string
+	tree.Each(func(node Node[Value]) {
+		sorted = append(sorted, string(node.Key()))
+	}, TraverseLeaf)
+
+	for _, pattern := range []string{"ab*", "*ness", "?a?e", "[xyz]*[^aeiou]", "*a*b*c*", "un*able", "Q*", "zyzzyva*"} {
+		var expected []string
+		for _, key := range sorted {
+			if ok, _ := path.Match(pattern, key); ok {
+				expected = append(expected, key)
+			}
+		}
+		assert.Equal(t, expected, globKeys(t, tree, pattern), pattern)
+	}
+}
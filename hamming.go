@@ -0,0 +1,76 @@
+// Copyright © 2019, Oleksandr Krykovliuk <k33nice@gmail.com>.
+// Use of this source code is governed by the
+// MIT license that can be found in the LICENSE file.
+
+package art
+
+import (
+	"math/bits"
+	"slices"
+)
+
+// The largest number of differing bits for which the children may be looked up directly
+// by the candidate bytes instead of being scanned.
+const hammingLookupBits = 2
+
+// Matches the paths against the query, the state is the budget of differing bits left.
+type hammingMatcher struct {
+	query []byte
+}
+
+// Calls the passed in callback for every leaf whose key has the same length as the query
+// and differs from it in at most maxBits bits, in key order.
+func (t *tree[V]) WithinHamming(query Key, maxBits int, callback Callback[V]) {
+	if maxBits < 0 {
+		return
+	}
+	matchHelper[V, int](t, t.root, &hammingMatcher{query: query}, 0, maxBits, callback)
+}
+
+// Spends the budget on the differing bits of the byte.
+// A subtree is pruned once the budget is exceeded or the path gets longer than the query.
+func (m *hammingMatcher) step(budget, depth int, b byte) (int, bool) {
+	if depth >= len(m.query) {
+		return 0, false
+	}
+	budget -= bits.OnesCount8(b ^ m.query[depth])
+	return budget, budget >= 0
+}
+
+// Keys shorter than the query are reached with the budget left as well.
+func (m *hammingMatcher) accepts(_ int, key Key) bool {
+	return len(key) == len(m.query)
+}
+
+func (m *hammingMatcher) acceptsRest(int) bool {
+	return false
+}
+
+// Only the terminal child has the same length as the query once the path is as long as the query,
+// and there are only a few candidates for a small budget.
+func (m *hammingMatcher) literals(budget, depth int) ([]byte, bool) {
+	if depth >= len(m.query) {
+		return nil, true
+	}
+	if budget <= hammingLookupBits {
+		return hammingCandidates(m.query[depth], budget), true
+	}
+	return nil, false
+}
+
+// Returns the bytes that differ from the passed in one in at most maxBits bits, in ascending order.
+func hammingCandidates(b byte, maxBits int) []byte {
+	candidates := []byte{b}
+
+	// Every set of flipped bits is produced once by deciding on the bits one by one.
+	for bit := 0; bit < 8; bit++ {
+		for _, c := range candidates {
+			if bits.OnesCount8(c^b) < maxBits {
+				candidates = append(candidates, c^(1<<bit))
+			}
+		}
+	}
+
+	slices.Sort(candidates)
+	return candidates
+}
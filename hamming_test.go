@@ -0,0 +1,81 @@
+// Copyright © 2019, Oleksandr Krykovliuk <k33nice@gmail.com>.
+// Use of this source code is governed by the
+// MIT license that can be found in the LICENSE file.
+
+package art
+
+import (
+	"encoding/binary"
+	"math/bits"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func hammingKeys(tree *tree[Value], query Key, maxBits int) []string {
+	return searchKeys(func(callback Callback[Value]) {
+		tree.WithinHamming(query, maxBits, callback)
+	})
+}
+
+// WithinHamming should only return keys of the same length within the distance.
+func TestWithinHamming(t *testing.T) {
+	tree := newArt[Value]()
+	for _, key := range []Key{{0x00}, {0x01}, {0x03}, {0xff}, {0x00, 0x00}, {0x00, 0x01}, {0x01, 0x00}, {0x0f, 0xf0}, {}} {
+		tree.Insert(key, nil)
+	}
+
+	assert.Equal(t, []string{"\x00"}, hammingKeys(tree, Key{0x00}, 0))
+	assert.Equal(t, []string{"\x00", "\x01"}, hammingKeys(tree, Key{0x00}, 1))
+	assert.Equal(t, []string{"\x00", "\x01", "\x03"}, hammingKeys(tree, Key{0x00}, 2))
+	assert.Equal(t, []string{"\x00\x00", "\x00\x01", "\x01\x00"}, hammingKeys(tree, Key{0x00, 0x00}, 1))
+	assert.Equal(t, []string{"\x0f\xf0"}, hammingKeys(tree, Key{0x0f, 0xf1}, 1))
+	assert.Equal(t, []string{""}, hammingKeys(tree, Key{}, 3))
+	assert.Empty(t, hammingKeys(tree, Key{0x00, 0x00, 0x00}, 24))
+	assert.Empty(t, hammingKeys(tree, Key{0x00}, -1))
+}
+
+// The candidate bytes should be every byte within the distance in ascending order.
+func TestHammingCandidates(t *testing.T) {
+	for maxBits := 0; maxBits <= 8; maxBits++ {
+		var expected []byte
+		for b := 0; b < 256; b++ {
+			if bits.OnesCount8(byte(b)^0x5a) <= maxBits {
+				expected = append(expected, byte(b))
+			}
+		}
+		assert.Equal(t, expected, hammingCandidates(0x5a, maxBits))
+	}
+}
+
+// WithinHamming over random fingerprints should agree with comparing every fingerprint.
+func TestWithinHammingFingerprints(t *testing.T) {
+	rng := rand.New(rand.NewSource(11))
+	tree := newArt[Value]()
+
+	var fingerprints []uint64
+	for i := 0; i < 20000; i++ {
+		// Fingerprints are clustered, so some of them are close to each other.
+		fp := rng.Uint64()
+		if i > 0 && i%2 == 0 {
+			fp = fingerprints[rng.Intn(len(fingerprints))] ^ (1 << rng.Intn(64)) ^ (1 << rng.Intn(64))
+		}
+		fingerprints = append(fingerprints, fp)
+		tree.Insert(binary.BigEndian.AppendUint64(nil, fp), fp)
+	}
+
+	for i := 0; i < 50; i++ {
+		query := fingerprints[rng.Intn(len(fingerprints))] ^ (1 << rng.Intn(64))
+		for _, maxBits := range []int{0, 1, 3, 8} {
+			var expected []string
+			tree.Each(func(node Node[Value]) {
+				if bits.OnesCount64(node.Value().(uint64)^query) <= maxBits {
+					expected = append(expected, string(node.Key()))
+				}
+			}, TraverseLeaf)
+
+			assert.Equal(t, expected, hammingKeys(tree, binary.BigEndian.AppendUint64(nil, query), maxBits))
+		}
+	}
+}
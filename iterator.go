@@ -0,0 +1,187 @@
+// Copyright © 2019, Oleksandr Krykovliuk <k33nice@gmail.com>.
+// Use of this source code is governed by the
+// MIT license that can be found in the LICENSE file.
+
+package art
+
+import "bytes"
+
+// Iterator - delineate a pull-style iterator over the leaves of the tree in key order.
+//
+// A new iterator is not positioned: Next moves it to the smallest key and Prev to the largest one.
+// Once the iterator runs past either end it becomes invalid and not positioned again.
+// Any modification of the tree invalidates its iterators.
+type Iterator[V any] interface {
+	// Seek - moves the iterator to the smallest key that is greater or equal to the passed in key.
+	Seek(key Key) bool
+	// Next - moves the iterator to the next key.
+	Next() bool
+	// Prev - moves the iterator to the previous key.
+	Prev() bool
+	// Valid - reports whether the iterator is positioned at a key.
+	Valid() bool
+	// Key - returns the key at the current position, or nil if the iterator is invalid.
+	Key() Key
+	// Value - returns the value at the current position, or the zero value if the iterator is invalid.
+	Value() V
+}
+
+// A single step of the path from the root to the current leaf:
+// an inner node and the position of its child that the path goes through.
+type iteratorFrame[V any] struct {
+	node *artNode[V]
+	pos  int
+}
+
+type iterator[V any] struct {
+	tree  *tree[V]
+	stack []iteratorFrame[V]
+	leaf  *artNode[V]
+}
+
+func newIterator[V any](t *tree[V]) *iterator[V] {
+	return &iterator[V]{tree: t}
+}
+
+func (it *iterator[V]) Valid() bool {
+	return it.leaf != nil
+}
+
+func (it *iterator[V]) Key() Key {
+	if it.leaf == nil {
+		return nil
+	}
+	return it.leaf.leaf().key
+}
+
+func (it *iterator[V]) Value() V {
+	if it.leaf == nil {
+		var zero V
+		return zero
+	}
+	return it.leaf.leaf().value
+}
+
+func (it *iterator[V]) Next() bool {
+	if it.leaf == nil {
+		it.reset()
+		return it.first(it.tree.root)
+	}
+	return it.next()
+}
+
+func (it *iterator[V]) Prev() bool {
+	if it.leaf == nil {
+		it.reset()
+		return it.last(it.tree.root)
+	}
+	return it.prev()
+}
+
+// Seek descends the tree the same way as searchHelper does, remembering the path.
+// Once the path diverges from the key, the iterator is moved either
+// to the smallest leaf of the current subtree if it's greater than the key,
+// or past the biggest leaf of the current subtree otherwise.
+func (it *iterator[V]) Seek(key Key) bool {
+	it.reset()
+
+	current := it.tree.root
+	depth := 0
+	for current != nil {
+		if current.isLeaf() {
+			it.leaf = current
+			if bytes.Compare(current.leaf().key, key) < 0 {
+				return it.next()
+			}
+			return true
+		}
+
+		if current.node().prefixLen != 0 {
+			switch comparePath(current.fullPrefix(depth), key, depth) {
+			case 1:
+				return it.first(current)
+			case -1:
+				it.last(current)
+				return it.next()
+			}
+			depth += current.node().prefixLen
+		}
+
+		// The key is consumed completely, so every key below is greater or equal to it.
+		if depth >= len(key) {
+			return it.first(current)
+		}
+
+		pos, child := current.nextChild(current.childPosition(key[depth]))
+		if child == nil {
+			it.last(current)
+			return it.next()
+		}
+
+		it.stack = append(it.stack, iteratorFrame[V]{node: current, pos: pos})
+		if *(current.findChild(key[depth])) != child {
+			return it.first(child)
+		}
+
+		current = child
+		depth++
+	}
+
+	return false
+}
+
+// Drops the current position of the iterator.
+func (it *iterator[V]) reset() {
+	it.stack = it.stack[:0]
+	it.leaf = nil
+}
+
+// Moves the iterator to the smallest leaf of the passed in subtree.
+func (it *iterator[V]) first(current *artNode[V]) bool {
+	for current != nil && !current.isLeaf() {
+		pos, child := current.nextChild(0)
+		it.stack = append(it.stack, iteratorFrame[V]{node: current, pos: pos})
+		current = child
+	}
+	it.leaf = current
+	return it.leaf != nil
+}
+
+// Moves the iterator to the biggest leaf of the passed in subtree.
+func (it *iterator[V]) last(current *artNode[V]) bool {
+	for current != nil && !current.isLeaf() {
+		pos, child := current.prevChild(current.lastPosition())
+		it.stack = append(it.stack, iteratorFrame[V]{node: current, pos: pos})
+		current = child
+	}
+	it.leaf = current
+	return it.leaf != nil
+}
+
+// Moves the iterator to the leaf that follows the current one.
+func (it *iterator[V]) next() bool {
+	for len(it.stack) > 0 {
+		top := &it.stack[len(it.stack)-1]
+		if pos, child := top.node.nextChild(top.pos + 1); child != nil {
+			top.pos = pos
+			return it.first(child)
+		}
+		it.stack = it.stack[:len(it.stack)-1]
+	}
+	it.leaf = nil
+	return false
+}
+
+// Moves the iterator to the leaf that precedes the current one.
+func (it *iterator[V]) prev() bool {
+	for len(it.stack) > 0 {
+		top := &it.stack[len(it.stack)-1]
+		if pos, child := top.node.prevChild(top.pos - 1); child != nil {
+			top.pos = pos
+			return it.last(child)
+		}
+		it.stack = it.stack[:len(it.stack)-1]
+	}
+	it.leaf = nil
+	return false
+}
@@ -0,0 +1,190 @@
+// Copyright © 2019, Oleksandr Krykovliuk <k33nice@gmail.com>.
+// Use of this source code is governed by the
+// MIT license that can be found in the LICENSE file.
+
+package art
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/k33nice/libart/internal/test"
+	"github.com/stretchr/testify/assert"
+)
+
+// An iterator of an empty tree should never become valid.
+func TestIteratorEmptyTree(t *testing.T) {
+	it := newArt[Value]().Iterator()
+
+	assert.False(t, it.Valid())
+	assert.False(t, it.Next())
+	assert.False(t, it.Prev())
+	assert.False(t, it.Seek(Key("key")))
+	assert.Nil(t, it.Key())
+	assert.Nil(t, it.Value())
+}
+
+// An iterator should move forward and backward over the leaves of all node types.
+func TestIteratorNextPrevForAllNodeTypes(t *testing.T) {
+	for _, total := range []int{1, 4, 16, 48, 256} {
+		tree := newArt[Value]()
+		for i := 0; i < total; i++ {
+			tree.Insert(Key{byte(i)}, i)
+		}
+
+		it := tree.Iterator()
+		for i := 0; i < total; i++ {
+			assert.True(t, it.Next())
+			assert.Equal(t, Key{byte(i)}, it.Key())
+			assert.Equal(t, i, it.Value())
+		}
+		assert.False(t, it.Next())
+		assert.False(t, it.Valid())
+
+		for i := total - 1; i >= 0; i-- {
+			assert.True(t, it.Prev())
+			assert.Equal(t, Key{byte(i)}, it.Key())
+		}
+		assert.False(t, it.Prev())
+	}
+}
+
+// Seek should position the iterator at the smallest key that is greater or equal to the target.
+func TestIteratorSeek(t *testing.T) {
+	tree := newArt[Value]()
+	for _, w := range []string{"app", "api", "apple", "application", "apply", "banana"} {
+		tree.Insert(Key(w), w)
+	}
+
+	it := tree.Iterator()
+
+	assert.True(t, it.Seek(Key("apple")))
+	assert.Equal(t, Key("apple"), it.Key())
+
+	assert.True(t, it.Seek(Key("appla")))
+	assert.Equal(t, Key("apple"), it.Key())
+
+	assert.True(t, it.Seek(Key("applf")))
+	assert.Equal(t, Key("application"), it.Key())
+
+	assert.True(t, it.Seek(Key("applx")))
+	assert.Equal(t, Key("apply"), it.Key())
+
+	assert.True(t, it.Seek(Key("applz")))
+	assert.Equal(t, Key("banana"), it.Key())
+
+	assert.True(t, it.Seek(Key("ap")))
+	assert.Equal(t, Key("api"), it.Key())
+
+	assert.True(t, it.Seek(Key("aq")))
+	assert.Equal(t, Key("banana"), it.Key())
+
+	assert.True(t, it.Seek(nil))
+	assert.Equal(t, Key("api"), it.Key())
+
+	assert.False(t, it.Seek(Key("c")))
+	assert.False(t, it.Valid())
+
+	// Seek followed by both directions of iteration.
+	assert.True(t, it.Seek(Key("applf")))
+	assert.Equal(t, Key("application"), it.Key())
+	assert.True(t, it.Prev())
+	assert.Equal(t, Key("apple"), it.Key())
+	assert.True(t, it.Next())
+	assert.True(t, it.Next())
+	assert.Equal(t, Key("apply"), it.Key())
+	assert.True(t, it.Next())
+	assert.Equal(t, Key("banana"), it.Key())
+	assert.False(t, it.Next())
+}
+
+// Seek should work when the target diverges within a long compressed path.
+func TestIteratorSeekWithinLongCompressedPath(t *testing.T) {
+	tree := newArt[Value]()
+	tree.Insert(Key("tenant:0123456789:user:1"), 1)
+	tree.Insert(Key("tenant:0123456789:user:2"), 2)
+
+	it := tree.Iterator()
+
+	assert.True(t, it.Seek(Key("tenant:0123456789:a")))
+	assert.Equal(t, 1, it.Value())
+
+	assert.True(t, it.Seek(Key("tenant:0123456789:user:15")))
+	assert.Equal(t, 2, it.Value())
+
+	assert.False(t, it.Seek(Key("tenant:0123456789:z")))
+}
+
+// Iterating over the dictionary should return the same words as an ordered traversal
+// and Seek should find the same successors as a binary search.
+func TestIteratorManyWords(t *testing.T) {
+	tree := newArt[Value]()
+
+	words := test.LoadTestFile("test/data/words.txt")
+	sorted := make([]string, 0, len(words))
+	for _, w := range words {
+		tree.Insert(w, w)
+		sorted = append(sorted, string(w))
+	}
+	sort.Strings(sorted)
+
+	it := tree.Iterator()
+	for _, w := range sorted {
+		assert.True(t, it.Next())
+		assert.Equal(t, w, string(it.Key()))
+	}
+	assert.False(t, it.Next())
+
+	for i := len(sorted) - 1; i >= 0; i-- {
+		assert.True(t, it.Prev())
+		assert.Equal(t, sorted[i], string(it.Key()))
+	}
+	assert.False(t, it.Prev())
+
+	for _, target := range []string{"A", "Aa", "interstellar", "mzzz", "qwerty", "zyt", "zz"} {
+		i := sort.SearchStrings(sorted, target)
+		if i == len(sorted) {
+			assert.False(t, it.Seek(Key(target)))
+			continue
+		}
+		assert.True(t, it.Seek(Key(target)))
+		assert.Equal(t, sorted[i], string(it.Key()), target)
+		if i > 0 {
+			assert.True(t, it.Prev())
+			assert.Equal(t, sorted[i-1], string(it.Key()), target)
+		}
+	}
+}
+
+// Iterators of two trees should be usable together to merge-join them.
+func TestIteratorMergeJoin(t *testing.T) {
+	left, right := newArt[Value](), newArt[Value]()
+	for i := 0; i < 300; i += 2 {
+		left.Insert(Key{byte(i / 256), byte(i)}, i)
+	}
+	for i := 0; i < 300; i += 3 {
+		right.Insert(Key{byte(i / 256), byte(i)}, i)
+	}
+
+	var joined []int
+	l, r := left.Iterator(), right.Iterator()
+	l.Next()
+	r.Next()
+	for l.Valid() && r.Valid() {
+		switch c := string(l.Key()); {
+		case c < string(r.Key()):
+			l.Seek(r.Key())
+		case c > string(r.Key()):
+			r.Seek(l.Key())
+		default:
+			joined = append(joined, l.Value().(int))
+			l.Next()
+			r.Next()
+		}
+	}
+
+	assert.Len(t, joined, 50)
+	for i, value := range joined {
+		assert.Equal(t, i*6, value)
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright © 2019, Oleksandr Krykovliuk <k33nice@gmail.com>.
+// Use of this source code is governed by the
+// MIT license that can be found in the LICENSE file.
+
+package art
+
+// A state machine that is run over the bytes of the path while descending the tree,
+// so the subtrees whose paths can't lead to an accepted key are pruned.
+// The passed in depth is the index of the byte within the key.
+type pathMatcher[S any] interface {
+	// Returns the state after appending the byte to the path,
+	// and false if no key starting with the new path can be accepted.
+	step(state S, depth int, b byte) (S, bool)
+	// Reports whether the key is accepted, given the state after consuming it,
+	// or any state that accepts the rest.
+	accepts(state S, key Key) bool
+	// Reports whether every key starting with the path may be accepted,
+	// so the steps are skipped for the whole subtree.
+	acceptsRest(state S) bool
+	// Returns the sorted bytes the next step may consume,
+	// or false if there are too many of them to look up the children directly.
+	literals(state S, depth int) ([]byte, bool)
+}
+
+// Recursive helper that descends the tree along with the state of the passed in matcher,
+// and calls the callback for every accepted leaf in key order.
+func matchHelper[V, S any](t *tree[V], current *artNode[V], m pathMatcher[S], depth int, state S, callback Callback[V]) {
+	if current == nil {
+		return
+	}
+
+	if m.acceptsRest(state) {
+		matchSubtree(t, current, m, state, callback)
+		return
+	}
+
+	// Leaves are reached due to lazy expansion, so the rest of the key is matched byte by byte.
+	if current.isLeaf() {
+		key := current.leaf().key
+		for i := depth; i < len(key) && !m.acceptsRest(state); i++ {
+			var alive bool
+			if state, alive = m.step(state, i, key[i]); !alive {
+				return
+			}
+		}
+		if m.accepts(state, key) {
+			callback(current)
+		}
+		return
+	}
+
+	// The compressed path is matched the same way as the keys of the children.
+	for i, b := range current.fullPrefix(depth) {
+		var alive bool
+		if state, alive = m.step(state, depth+i, b); !alive {
+			return
+		}
+	}
+	depth += current.node().prefixLen
+
+	if m.acceptsRest(state) {
+		matchSubtree(t, current, m, state, callback)
+		return
+	}
+
+	// The key of the terminal child is equal to the path.
+	if terminal := *current.terminal(); terminal != nil && m.accepts(state, terminal.leaf().key) {
+		callback(terminal)
+	}
+
+	visit := func(key byte, child *artNode[V]) bool {
+		if next, alive := m.step(state, depth, key); alive {
+			matchHelper(t, child, m, depth+1, next, callback)
+		}
+		return true
+	}
+
+	// Look up the children directly if there are fewer candidates than children.
+	if literals, ok := m.literals(state, depth); ok && len(literals) < current.node().size {
+		for _, key := range literals {
+			if child := *(current.findChild(key)); child != nil {
+				visit(key, child)
+			}
+		}
+		return
+	}
+
+	current.eachChildBetween(0, 255, false, visit)
+}
+
+// Calls the callback for every leaf of the subtree the passed in state accepts.
+func matchSubtree[V, S any](t *tree[V], current *artNode[V], m pathMatcher[S], state S, callback Callback[V]) {
+	t.eachHelper(current, func(node Node[V]) error {
+		if m.accepts(state, node.Key()) {
+			callback(node)
+		}
+		return nil
+	}, leavesOnly(traverseOptions(nil)))
+}
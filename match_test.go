@@ -0,0 +1,72 @@
+// Copyright © 2019, Oleksandr Krykovliuk <k33nice@gmail.com>.
+// Use of this source code is governed by the
+// MIT license that can be found in the LICENSE file.
+
+package art
+
+import (
+	"testing"
+
+	"github.com/k33nice/libart/internal/test"
+	"github.com/stretchr/testify/assert"
+)
+
+// Collects the keys of the leaves the search passes in to the callback, in the order of the calls.
+func searchKeys(search func(callback Callback[Value])) []string {
+	var keys []string
+	search(func(node Node[Value]) {
+		keys = append(keys, string(node.Key()))
+	})
+	return keys
+}
+
+// Counts the steps of the wrapped matcher.
+type countingMatcher[S any] struct {
+	pathMatcher[S]
+	steps int
+}
+
+func (m *countingMatcher[S]) step(state S, depth int, b byte) (S, bool) {
+	m.steps++
+	return m.pathMatcher.step(state, depth, b)
+}
+
+// Accepts the keys starting with the prefix, the state is the number of its matched bytes.
+type prefixMatcher struct {
+	prefix []byte
+}
+
+func (m *prefixMatcher) step(matched, depth int, b byte) (int, bool) {
+	return matched + 1, matched == depth && b == m.prefix[depth]
+}
+
+func (m *prefixMatcher) accepts(matched int, _ Key) bool {
+	return matched >= len(m.prefix)
+}
+
+func (m *prefixMatcher) acceptsRest(matched int) bool {
+	return matched >= len(m.prefix)
+}
+
+func (m *prefixMatcher) literals(_, depth int) ([]byte, bool) {
+	return m.prefix[depth : depth+1], true
+}
+
+// The walker should visit the same keys as EachPrefix does, with the shortcuts of the matcher.
+func TestMatchHelper(t *testing.T) {
+	tree := newArt[Value]()
+	words := test.LoadTestFile("test/data/words.txt")
+	for _, w := range words {
+		tree.Insert(w, w)
+	}
+
+	for _, prefix := range []string{"", "a", "ab", "abandon", "abandonment", "abandonments", "zyzzyva", "qz"} {
+		expected := searchKeys(func(callback Callback[Value]) {
+			tree.EachPrefix(Key(prefix), callback)
+		})
+		actual := searchKeys(func(callback Callback[Value]) {
+			matchHelper[Value, int](tree, tree.root, &prefixMatcher{prefix: Key(prefix)}, 0, 0, callback)
+		})
+		assert.Equal(t, expected, actual, prefix)
+	}
+}
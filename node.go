@@ -30,56 +30,63 @@ const (
 	maxPrefixLen = 10
 )
 
+// Every inner node has an extra slot after its children for the terminal child.
+// The terminal child is a leaf whose key ends exactly at the node,
+// i.e. its key is equal to the path from the root to the node including the compressed path.
+// It's the smallest key of the subtree, and it isn't counted in the size of the node.
+
 type node struct {
 	size      int
 	prefixLen int
 	prefix    [maxPrefixLen]byte
+	// The number of leaves in the subtree, including the terminal child.
+	count int
 }
 
-type node4 struct {
+type node4[V any] struct {
 	node
 	keys     [node4Max]byte
-	children [node4Max + 1]*artNode
+	children [node4Max + 1]*artNode[V]
 }
 
 // Node with 16 children
-type node16 struct {
+type node16[V any] struct {
 	node
 	keys     [node16Max]byte
-	children [node16Max + 1]*artNode
+	children [node16Max + 1]*artNode[V]
 }
 
 // Node with 48 children
-type node48 struct {
+type node48[V any] struct {
 	node
 	keys     [node256Max]byte
-	children [node48Max + 1]*artNode
+	children [node48Max + 1]*artNode[V]
 }
 
 // Node with 256 children
-type node256 struct {
+type node256[V any] struct {
 	node
-	children [node256Max + 1]*artNode
+	children [node256Max + 1]*artNode[V]
 }
 
 // Leaf node with variable key length
-type leaf struct {
+type leaf[V any] struct {
 	key   Key
-	value interface{}
+	value V
 }
 
 // Defines a single artNode and its attributes.
-type artNode struct {
+type artNode[V any] struct {
 	kind Kind
 	ref  unsafe.Pointer
 }
 
-func newLeafNode(key []byte, value interface{}) *artNode {
+func newLeafNode[V any](key []byte, value V) *artNode[V] {
 	newKey := make([]byte, len(key))
 	copy(newKey, key)
-	return &artNode{
+	return &artNode[V]{
 		kind: Leaf,
-		ref:  unsafe.Pointer(&leaf{key: newKey, value: value}),
+		ref:  unsafe.Pointer(&leaf[V]{key: newKey, value: value}),
 	}
 }
 
@@ -87,8 +94,8 @@ func newLeafNode(key []byte, value interface{}) *artNode {
 // pointers and uses an array of length 4 for keys and another
 // array of the same length for pointers. The keys and pointers
 // are stored at corresponding positions and the keys are sorted.
-func newNode4() *artNode {
-	return &artNode{kind: Node4, ref: unsafe.Pointer(&node4{})}
+func newNode4[V any]() *artNode[V] {
+	return &artNode[V]{kind: Node4, ref: unsafe.Pointer(&node4[V]{})}
 }
 
 // From the specification: This node type is used for storing between 5 and
@@ -97,8 +104,8 @@ func newNode4() *artNode {
 // both arrays have space for 16 entries. A key can be found
 // efﬁciently with binary search or, on modern hardware, with
 // parallel comparisons using SIMD instructions.
-func newNode16() *artNode {
-	return &artNode{kind: Node16, ref: unsafe.Pointer(&node16{})}
+func newNode16[V any]() *artNode[V] {
+	return &artNode[V]{kind: Node16, ref: unsafe.Pointer(&node16[V]{})}
 }
 
 // From the specification: As the number of entries in a node increases,
@@ -108,8 +115,8 @@ func newNode16() *artNode {
 // with key bytes directly. If a node has between 17 and 48 child
 // pointers, this array stores indexes into a second array which
 // contains up to 48 pointers.
-func newNode48() *artNode {
-	return &artNode{kind: Node48, ref: unsafe.Pointer(&node48{})}
+func newNode48[V any]() *artNode[V] {
+	return &artNode[V]{kind: Node48, ref: unsafe.Pointer(&node48[V]{})}
 }
 
 // From the specification: The largest node type is simply an array of 256
@@ -119,39 +126,40 @@ func newNode48() *artNode {
 // No additional indirection is necessary. If most entries are not
 // null, this representation is also very space efﬁcient because
 // only pointers need to be stored.
-func newNode256() *artNode {
-	return &artNode{kind: Node256, ref: unsafe.Pointer(&node256{})}
+func newNode256[V any]() *artNode[V] {
+	return &artNode[V]{kind: Node256, ref: unsafe.Pointer(&node256[V]{})}
 }
 
-func (n *artNode) Key() Key {
+func (n *artNode[V]) Key() Key {
 	if n.isLeaf() {
 		return n.leaf().key
 	}
 	return nil
 }
 
-// Returns the value of the given node, or nil if it is not a leaf.
-func (n *artNode) Value() interface{} {
+// Returns the value of the given node, or the zero value if it is not a leaf.
+func (n *artNode[V]) Value() V {
 	if n.kind != Leaf {
-		return nil
+		var zero V
+		return zero
 	}
 	return n.leaf().value
 }
 
-func (n *artNode) Kind() Kind {
+func (n *artNode[V]) Kind() Kind {
 	return n.kind
 }
 
 // Returns whether or not this particular art node is full.
-func (n *artNode) isFull() bool {
+func (n *artNode[V]) isFull() bool {
 	return n.node().size == n.maxSize()
 }
 
 // Returns whether or not this particular art node is a leaf node.
-func (n *artNode) isLeaf() bool { return n.kind == Leaf }
+func (n *artNode[V]) isLeaf() bool { return n.kind == Leaf }
 
 // Returns whether or not the key stored in the leaf matches the passed in key.
-func (n *artNode) isMatch(key []byte) bool {
+func (n *artNode[V]) isMatch(key []byte) bool {
 
 	// Bail if user tries to compare  anything but a leaf node
 	if n.kind != Leaf {
@@ -165,20 +173,15 @@ func (n *artNode) isMatch(key []byte) bool {
 	return bytes.Compare(n.leaf().key[:len(key)], key) == 0
 }
 
-// Returns the number of bytes that differ between the passed in key
+// Returns the number of bytes that match between the passed in key
 // and the compressed path of the current node at the specified depth.
-func (n *artNode) prefixMismatch(key []byte, depth int) int {
+// The end of the key is a mismatch as well.
+func (n *artNode[V]) prefixMismatch(key []byte, depth int) int {
 	index := 0
 
 	if n.node().prefixLen > maxPrefixLen {
-		var keyChar byte
 		for ; index < maxPrefixLen; index++ {
-			if depth+index < 0 || depth+index >= len(key) {
-				keyChar = byte(0)
-			} else {
-				keyChar = key[depth+index]
-			}
-			if keyChar != n.node().prefix[index] {
+			if depth+index >= len(key) || key[depth+index] != n.node().prefix[index] {
 				return index
 			}
 		}
@@ -186,21 +189,15 @@ func (n *artNode) prefixMismatch(key []byte, depth int) int {
 		minKey := n.minimum().leaf().key
 
 		for ; index < n.node().prefixLen; index++ {
-			if key[depth+index] != minKey[depth+index] {
+			if depth+index >= len(key) || key[depth+index] != minKey[depth+index] {
 				return index
 			}
 		}
 
 	} else {
 
-		var keyChar byte
 		for ; index < n.node().prefixLen; index++ {
-			if depth+index < 0 || depth+index >= len(key) {
-				keyChar = byte(0)
-			} else {
-				keyChar = key[depth+index]
-			}
-			if keyChar != n.node().prefix[index] {
+			if depth+index >= len(key) || key[depth+index] != n.node().prefix[index] {
 				return index
 			}
 		}
@@ -209,7 +206,18 @@ func (n *artNode) prefixMismatch(key []byte, depth int) int {
 	return index
 }
 
-func (n *artNode) index(key byte) int {
+// Returns the compressed path of the current node located at the specified depth.
+// Only the first maxPrefixLen bytes of the path are stored in the node itself,
+// so the longer paths are restored from the minimum leaf.
+func (n *artNode[V]) fullPrefix(depth int) []byte {
+	node := n.node()
+	if node.prefixLen <= maxPrefixLen {
+		return node.prefix[:node.prefixLen]
+	}
+	return n.minimum().leaf().key[depth : depth+node.prefixLen]
+}
+
+func (n *artNode[V]) index(key byte) int {
 	switch n.kind {
 	case Node4:
 		// artNodes of type Node4 have a relatively simple lookup algorithm since
@@ -221,7 +229,8 @@ func (n *artNode) index(key byte) int {
 			}
 		}
 	case Node16:
-		return bytes.IndexByte(n.node16().keys[:], key)
+		node := n.node16()
+		return bytes.IndexByte(node.keys[:node.size], key)
 
 	case Node48:
 		// artNodes of type Node48 store the indicies in which to access their children
@@ -243,14 +252,20 @@ func (n *artNode) index(key byte) int {
 	return -1
 }
 
-// Define nullNode only once, thus do not make redundant allocations
-var nullNode *artNode
+// Define nullNode only once, thus do not make redundant allocations.
+// It's shared between trees of all value types, since nil pointers to nodes look the same for all of them.
+var nullNode unsafe.Pointer
+
+// Returns a reference to the shared nil node. It must never be written to.
+func nullChild[V any]() **artNode[V] {
+	return (**artNode[V])(unsafe.Pointer(&nullNode))
+}
 
 // FindChild returns a pointer to the child that matches the passed in key,
 // or nil if not present.
-func (n *artNode) findChild(key byte) **artNode {
+func (n *artNode[V]) findChild(key byte) **artNode[V] {
 	if n == nil {
-		return &nullNode
+		return nullChild[V]()
 	}
 
 	var idx int
@@ -258,12 +273,12 @@ func (n *artNode) findChild(key byte) **artNode {
 	case Node4, Node16, Node48:
 		idx = n.index(key)
 		if idx < 0 {
-			return &nullNode
+			return nullChild[V]()
 		}
 	case Node256:
 		idx = int(key)
 		if n.node256().children[idx] == nil {
-			return &nullNode
+			return nullChild[V]()
 		}
 	}
 
@@ -280,12 +295,202 @@ func (n *artNode) findChild(key byte) **artNode {
 		}
 	}
 
-	return &nullNode
+	return nullChild[V]()
+}
+
+// Returns a pointer to the terminal child slot of the current node.
+func (n *artNode[V]) terminal() **artNode[V] {
+	switch n.kind {
+	case Node4:
+		return &n.node4().children[node4Max]
+	case Node16:
+		return &n.node16().children[node16Max]
+	case Node48:
+		return &n.node48().children[node48Max]
+	case Node256:
+		return &n.node256().children[node256Max]
+	}
+	return nullChild[V]()
+}
+
+// findChildAt returns a pointer to the child that continues the passed in key at the specified depth.
+// It's the terminal child if the key ends at this depth.
+func (n *artNode[V]) findChildAt(key []byte, depth int) **artNode[V] {
+	if depth >= len(key) {
+		return n.terminal()
+	}
+	return n.findChild(key[depth])
+}
+
+// eachChildBetween calls the passed in function for every child
+// with a key between lo and hi inclusively in ascending order of keys,
+// or in descending order if reverse is set. The iteration stops once the function returns false.
+func (n *artNode[V]) eachChildBetween(lo, hi byte, reverse bool, fn func(key byte, child *artNode[V]) bool) {
+	switch n.kind {
+	case Node4, Node16:
+		var keys []byte
+		var children []*artNode[V]
+		if n.kind == Node4 {
+			node := n.node4()
+			keys, children = node.keys[:node.size], node.children[:node.size]
+		} else {
+			node := n.node16()
+			keys, children = node.keys[:node.size], node.children[:node.size]
+		}
+
+		// Keys are sorted, so find the bounds with binary search.
+		from := sort.Search(len(keys), func(i int) bool { return keys[i] >= lo })
+		to := sort.Search(len(keys), func(i int) bool { return keys[i] > hi })
+		for i := from; i < to; i++ {
+			k := i
+			if reverse {
+				k = from + to - 1 - i
+			}
+			if !fn(keys[k], children[k]) {
+				return
+			}
+		}
+
+	case Node48:
+		node := n.node48()
+		for i := int(lo); i <= int(hi); i++ {
+			k := i
+			if reverse {
+				k = int(lo) + int(hi) - i
+			}
+			if idx := node.keys[k]; idx > 0 {
+				if !fn(byte(k), node.children[idx-1]) {
+					return
+				}
+			}
+		}
+
+	case Node256:
+		node := n.node256()
+		for i := int(lo); i <= int(hi); i++ {
+			k := i
+			if reverse {
+				k = int(lo) + int(hi) - i
+			}
+			if child := node.children[k]; child != nil {
+				if !fn(byte(k), child) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Children of an inner node are addressed by positions in ascending order of their keys.
+// Position 0 refers to the terminal child, and the following positions
+// refer to the rest of children: for nodes of type Node4 and Node16 these are
+// the indexes of the sorted keys, for Node48 and Node256 these are the key bytes.
+// Both are shifted by one.
+
+// Returns the last valid child position of the current node.
+func (n *artNode[V]) lastPosition() int {
+	switch n.kind {
+	case Node4, Node16:
+		return n.node().size
+	case Node48, Node256:
+		return node256Max
+	}
+	return -1
+}
+
+// Returns the child at the passed in position, or nil if there is no such child.
+func (n *artNode[V]) childAt(pos int) *artNode[V] {
+	switch n.kind {
+	case Node4:
+		if pos == 0 {
+			return n.node4().children[node4Max]
+		}
+		return n.node4().children[pos-1]
+
+	case Node16:
+		if pos == 0 {
+			return n.node16().children[node16Max]
+		}
+		return n.node16().children[pos-1]
+
+	case Node48:
+		node := n.node48()
+		if pos == 0 {
+			return node.children[node48Max]
+		}
+		if idx := node.keys[pos-1]; idx > 0 {
+			return node.children[idx-1]
+		}
+
+	case Node256:
+		if pos == 0 {
+			return n.node256().children[node256Max]
+		}
+		return n.node256().children[pos-1]
+	}
+
+	return nil
+}
+
+// Returns the position at which the child with the passed in key is located
+// or would be located if it is absent.
+func (n *artNode[V]) childPosition(key byte) int {
+	switch n.kind {
+	case Node4:
+		node := n.node4()
+		return 1 + sort.Search(node.size, func(i int) bool { return node.keys[i] >= key })
+	case Node16:
+		node := n.node16()
+		return 1 + sort.Search(node.size, func(i int) bool { return node.keys[i] >= key })
+	}
+	return 1 + int(key)
+}
+
+// Returns the number of leaves in the subtree of the current node.
+func (n *artNode[V]) leafCount() int {
+	if n == nil {
+		return 0
+	}
+	if n.isLeaf() {
+		return 1
+	}
+	return n.node().count
+}
+
+// Returns the number of leaves in the subtrees of the children before the passed in position.
+func (n *artNode[V]) countBefore(pos int) int {
+	count := 0
+	for p := 0; p < pos; p++ {
+		count += n.childAt(p).leafCount()
+	}
+	return count
+}
+
+// Returns the first child at the passed in position or after it along with its position,
+// or nil if there is no such child.
+func (n *artNode[V]) nextChild(pos int) (int, *artNode[V]) {
+	for last := n.lastPosition(); pos <= last; pos++ {
+		if child := n.childAt(pos); child != nil {
+			return pos, child
+		}
+	}
+	return -1, nil
+}
+
+// Returns the last child at the passed in position or before it along with its position,
+// or nil if there is no such child.
+func (n *artNode[V]) prevChild(pos int) (int, *artNode[V]) {
+	for pos = min(pos, n.lastPosition()); pos >= 0; pos-- {
+		if child := n.childAt(pos); child != nil {
+			return pos, child
+		}
+	}
+	return -1, nil
 }
 
 // addChild adds the passed in node to the current artNode's children at the specified key.
 // The current node will grow if necessary in order for the insertion to take place.
-func (n *artNode) addChild(key byte, node *artNode) {
+func (n *artNode[V]) addChild(key byte, node *artNode[V]) {
 	switch n.kind {
 	case Node4:
 		n4 := n.node4()
@@ -362,9 +567,46 @@ func (n *artNode) addChild(key byte, node *artNode) {
 	}
 }
 
+// addLeafAt adds the passed in leaf as a child of the current artNode,
+// which begins at the specified depth of the leaf's key.
+// A leaf whose key ends at that depth becomes the terminal child.
+func (n *artNode[V]) addLeafAt(leaf *artNode[V], depth int) {
+	key := leaf.leaf().key
+	if depth >= len(key) {
+		*n.terminal() = leaf
+		return
+	}
+	n.addChild(key[depth], leaf)
+}
+
+// removeTerminal removes the terminal child of the current artNode
+// and shrinks it if it can't hold the rest of its children on its own anymore.
+func (n *artNode[V]) removeTerminal() {
+	*n.terminal() = nil
+
+	if n.node().size < n.minSize() {
+		n.shrink()
+	}
+}
+
+// Unlinks the children by the passed in keys, and the terminal child if terminal is set,
+// whose subtrees hold the passed in number of leaves. Some leaves have to remain below the node.
+// The count is updated first, since the node may be replaced by its child while shrinking.
+// The terminal child is removed before the other children, so the node stays an inner node
+// until the last removal.
+func (n *artNode[V]) unlink(removed int, terminal bool, keys ...byte) {
+	n.node().count -= removed
+	if terminal {
+		n.removeTerminal()
+	}
+	for _, key := range keys {
+		n.RemoveChild(key)
+	}
+}
+
 // RemoveChild remove the child by the passed in key is removed if found
 // and the current artNode is shrunk if it falls below its minimum size.
-func (n *artNode) RemoveChild(key byte) {
+func (n *artNode[V]) RemoveChild(key byte) {
 	switch n.kind {
 	case Node4:
 		node := n.node4()
@@ -442,10 +684,10 @@ func (n *artNode) RemoveChild(key byte) {
 // artNodes of type Node16 will grow to Node48.
 // artNodes of type Node48 will grow to Node256.
 // artNodes of type Node256 will not grow, as they are the biggest type of artNodes
-func (n *artNode) grow() {
+func (n *artNode[V]) grow() {
 	switch n.kind {
 	case Node4:
-		other := newNode16()
+		other := newNode16[V]()
 		other.copyMeta(n)
 		other16 := other.node16()
 		n4 := n.node4()
@@ -457,7 +699,7 @@ func (n *artNode) grow() {
 		n.replaceWith(other)
 
 	case Node16:
-		other := newNode48()
+		other := newNode48[V]()
 		other.copyMeta(n)
 		other48 := other.node48()
 		n16 := n.node16()
@@ -480,7 +722,7 @@ func (n *artNode) grow() {
 		n.replaceWith(other)
 
 	case Node48:
-		other := newNode256()
+		other := newNode256[V]()
 		other.copyMeta(n)
 		other256 := other.node256()
 		n48 := n.node48()
@@ -505,12 +747,23 @@ func (n *artNode) grow() {
 // artNodes of type Node4 will collapse into its first child.
 // If that child is not a leaf, it will concatenate its current prefix with that of its childs
 // before replacing itself.
-func (n *artNode) shrink() {
+// artNodes of type Node4 with a terminal child keep the only child,
+// and collapse into the terminal child once there are no other children.
+func (n *artNode[V]) shrink() {
 	switch n.kind {
 	case Node4:
+		n4 := n.node4()
+
+		// The key of the terminal child ends at this node, so the node can't be merged with its child.
+		if terminal := n4.children[node4Max]; terminal != nil {
+			if n4.size == 0 {
+				n.replaceWith(terminal)
+			}
+			return
+		}
+
 		// From the specification: If that node now has only one child, it is replaced by its child
 		// and the compressed path is adjusted.
-		n4 := n.node4()
 		other := n4.children[0]
 
 		if !other.isLeaf() {
@@ -534,20 +787,20 @@ func (n *artNode) shrink() {
 		n.replaceWith(other)
 
 	case Node16:
-		other := newNode4()
+		other := newNode4[V]()
 		other.copyMeta(n)
 		other.node4().size = 0
 
 		for i := 0; i < len(other.node4().keys); i++ {
 			other.node4().keys[i] = n.node16().keys[i]
 			other.node4().children[i] = n.node16().children[i]
-			other.node16().size++
+			other.node4().size++
 		}
 
 		n.replaceWith(other)
 
 	case Node48:
-		other := newNode16()
+		other := newNode16[V]()
 		other.copyMeta(n)
 		other.node16().size = 0
 
@@ -566,11 +819,11 @@ func (n *artNode) shrink() {
 		n.replaceWith(other)
 
 	case Node256:
-		other := newNode48()
+		other := newNode48[V]()
 		other.copyMeta(n)
 		other.node48().size = 0
 
-		for i := 0; i < len(n.node256().children); i++ {
+		for i := 0; i < node256Max; i++ {
 			child := n.node256().children[byte(i)]
 			if child != nil {
 				other.node48().children[other.node48().size] = child
@@ -585,7 +838,7 @@ func (n *artNode) shrink() {
 
 // Returns the longest number of bytes that match between the current node's prefix
 // and the passed in node at the specified depth.
-func (n *artNode) longestCommonPrefix(other *artNode, depth int) int {
+func (n *artNode[V]) longestCommonPrefix(other *artNode[V], depth int) int {
 	limit := min(len(n.leaf().key), len(other.leaf().key)) - depth
 
 	i := 0
@@ -598,7 +851,7 @@ func (n *artNode) longestCommonPrefix(other *artNode, depth int) int {
 }
 
 // Returns the minimum number of children for the current node.
-func (n *artNode) minSize() int {
+func (n *artNode[V]) minSize() int {
 	switch n.kind {
 	case Node4:
 		return node4Min
@@ -613,7 +866,7 @@ func (n *artNode) minSize() int {
 }
 
 // Returns the maximum number of children for the current node.
-func (n *artNode) maxSize() int {
+func (n *artNode[V]) maxSize() int {
 	switch n.kind {
 	case Node4:
 		return node4Max
@@ -631,11 +884,18 @@ func (n *artNode) maxSize() int {
 // The minimum child is determined by recursively traversing down the tree
 // by selecting the smallest possible byte in each child
 // until a leaf has been reached.
-func (n *artNode) minimum() *artNode {
+// The terminal child is the smallest one of its node.
+func (n *artNode[V]) minimum() *artNode[V] {
 	if n == nil {
 		return nil
 	}
 
+	if n.kind != Leaf {
+		if terminal := *n.terminal(); terminal != nil {
+			return terminal
+		}
+	}
+
 	switch n.kind {
 	case Leaf:
 		return n
@@ -672,11 +932,16 @@ func (n *artNode) minimum() *artNode {
 // The maximum child is determined by recursively traversing down the tree
 // by selecting the biggest possible byte in each child
 // until a leaf has been reached.
-func (n *artNode) maximum() *artNode {
+// The terminal child is the biggest one only if there are no other children.
+func (n *artNode[V]) maximum() *artNode[V] {
 	if n == nil {
 		return nil
 	}
 
+	if n.kind != Leaf && n.node().size == 0 {
+		return *n.terminal()
+	}
+
 	switch n.kind {
 	case Leaf:
 		return n
@@ -701,9 +966,9 @@ func (n *artNode) maximum() *artNode {
 	case Node256:
 
 		node := n.node256()
-		i := len(node.children) - 1
+		i := node256Max - 1
 
-		for i > 0 && node.children[byte(i)] == nil {
+		for i > 0 && node.children[i] == nil {
 			i--
 		}
 
@@ -714,38 +979,38 @@ func (n *artNode) maximum() *artNode {
 	return nil
 }
 
-func (n *artNode) node() *node {
+func (n *artNode[V]) node() *node {
 	return (*node)(n.ref)
 }
 
-func (n *artNode) node4() *node4 {
-	return (*node4)(n.ref)
+func (n *artNode[V]) node4() *node4[V] {
+	return (*node4[V])(n.ref)
 }
 
-func (n *artNode) node16() *node16 {
-	return (*node16)(n.ref)
+func (n *artNode[V]) node16() *node16[V] {
+	return (*node16[V])(n.ref)
 }
 
-func (n *artNode) node48() *node48 {
-	return (*node48)(n.ref)
+func (n *artNode[V]) node48() *node48[V] {
+	return (*node48[V])(n.ref)
 }
 
-func (n *artNode) node256() *node256 {
-	return (*node256)(n.ref)
+func (n *artNode[V]) node256() *node256[V] {
+	return (*node256[V])(n.ref)
 }
 
-func (n *artNode) leaf() *leaf {
-	return (*leaf)(n.ref)
+func (n *artNode[V]) leaf() *leaf[V] {
+	return (*leaf[V])(n.ref)
 }
 
 // Replaces the current node with the passed in artNode.
-func (n *artNode) replaceWith(other *artNode) {
+func (n *artNode[V]) replaceWith(other *artNode[V]) {
 	*n = *other
 }
 
-// Copies the prefix and size metadata from the passed in artNode
-// to the current node.
-func (n *artNode) copyMeta(src *artNode) {
+// Copies the prefix, size and count metadata along with the terminal child
+// from the passed in artNode to the current node.
+func (n *artNode[V]) copyMeta(src *artNode[V]) {
 	if src == nil {
 		return
 	}
@@ -753,6 +1018,8 @@ func (n *artNode) copyMeta(src *artNode) {
 	from := src.node()
 	to.size = from.size
 	to.prefixLen = from.prefixLen
+	to.count = from.count
+	*n.terminal() = *src.terminal()
 
 	for i, limit := 0, min(from.prefixLen, maxPrefixLen); i < limit; i++ {
 		to.prefix[i] = from.prefix[i]
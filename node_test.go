@@ -15,7 +15,7 @@ import (
 
 // A Leaf Node should be able to retreive its value
 func TestValue(t *testing.T) {
-	leaf := newLeafNode([]byte("foo"), "foo")
+	leaf := newLeafNode[Value]([]byte("foo"), "foo")
 
 	if leaf.Value() != "foo" {
 		t.Error("Unexpected value for leaf node")
@@ -25,7 +25,7 @@ func TestValue(t *testing.T) {
 
 // An artNode4 should be able to find the expected child element
 func TestAddChildAndFindChildForAllNodeTypes(t *testing.T) {
-	nodes := []*artNode{newNode4(), newNode16(), newNode48(), newNode256()}
+	nodes := []*artNode[Value]{newNode4[Value](), newNode16[Value](), newNode48[Value](), newNode256[Value]()}
 
 	// For each different type of node
 	for node := range nodes {
@@ -33,7 +33,7 @@ func TestAddChildAndFindChildForAllNodeTypes(t *testing.T) {
 
 		// Fill it up
 		for i := 0; i < n.maxSize(); i++ {
-			newChild := newLeafNode([]byte{byte(i)}, byte(i))
+			newChild := newLeafNode[Value]([]byte{byte(i)}, byte(i))
 			n.addChild(byte(i), newChild)
 		}
 
@@ -54,7 +54,7 @@ func TestAddChildAndFindChildForAllNodeTypes(t *testing.T) {
 // Index should be able to return the correct location of the child
 // at the specfied key for all inner node types
 func TestIndexForAllNodeTypes(t *testing.T) {
-	nodes := []*artNode{newNode4(), newNode16(), newNode48(), newNode256()}
+	nodes := []*artNode[Value]{newNode4[Value](), newNode16[Value](), newNode48[Value](), newNode256[Value]()}
 
 	// For each different type of node
 	for node := range nodes {
@@ -62,7 +62,7 @@ func TestIndexForAllNodeTypes(t *testing.T) {
 
 		// Fill it up
 		for i := 0; i < n.maxSize(); i++ {
-			newChild := newLeafNode([]byte{byte(i)}, byte(i))
+			newChild := newLeafNode[Value]([]byte{byte(i)}, byte(i))
 			n.addChild(byte(i), newChild)
 		}
 
@@ -76,8 +76,8 @@ func TestIndexForAllNodeTypes(t *testing.T) {
 
 // An artNode4 should be able to add a child, and then return the expected child reference.
 func TestArtNode4AddChild1AndFindChild(t *testing.T) {
-	n := newNode4()
-	n2 := newNode4()
+	n := newNode4[Value]()
+	n2 := newNode4[Value]()
 	n.addChild('a', n2)
 
 	assert.Equal(t, 1, n.node().size)
@@ -89,9 +89,9 @@ func TestArtNode4AddChild1AndFindChild(t *testing.T) {
 // An artNode4 should be able to add two child elements with differing prefixes
 // And preserve the sorted order of the keys.
 func TestArtNode4AddChildTwicePreserveSorted(t *testing.T) {
-	n := newNode4()
-	n2 := newNode4()
-	n3 := newNode4()
+	n := newNode4[Value]()
+	n2 := newNode4[Value]()
+	n3 := newNode4[Value]()
 	n.addChild('b', n2)
 	n.addChild('a', n3)
 
@@ -111,10 +111,10 @@ func TestArtNode4AddChildTwicePreserveSorted(t *testing.T) {
 // An artNode4 should be able to add 4 child elements with different prefixes
 // And preserve the sorted order of the keys.
 func TestArtNode4AddChild4PreserveSorted(t *testing.T) {
-	n := newNode4()
+	n := newNode4[Value]()
 
 	for i := 4; i > 0; i-- {
-		n.addChild(byte(i), newNode4())
+		n.addChild(byte(i), newNode4[Value]())
 	}
 
 	if n.node4().size < 4 {
@@ -129,7 +129,7 @@ func TestArtNode4AddChild4PreserveSorted(t *testing.T) {
 
 // Art Nodes of all types should grow to the next biggest size in sequence
 func TestGrow(t *testing.T) {
-	nodes := []*artNode{newNode4(), newNode16(), newNode48()}
+	nodes := []*artNode[Value]{newNode4[Value](), newNode16[Value](), newNode48[Value]()}
 	expectedTypes := []Kind{Node16, Node48, Node256}
 
 	for i := range nodes {
@@ -144,9 +144,9 @@ func TestGrow(t *testing.T) {
 
 // Art Nodes of all types should next smallest size in sequence
 func TestShrink(t *testing.T) {
-	// nodes := []*artNode{newNode256(), newNode48(), newNode16(), newNode4()}
+	// nodes := []*artNode[Value]{newNode256[Value](), newNode48[Value](), newNode16[Value](), newNode4[Value]()}
 	// expectedTypes := []Kind{Node48, Node16, Node4, Leaf}
-	nodes := []*artNode{newNode48()}
+	nodes := []*artNode[Value]{newNode48[Value]()}
 	expectedTypes := []Kind{Node16}
 
 	for i := range nodes {
@@ -154,11 +154,11 @@ func TestShrink(t *testing.T) {
 
 		for j := 0; j < node.minSize(); j++ {
 			if node.kind != Node4 {
-				node.addChild(byte(i), newNode4())
+				node.addChild(byte(i), newNode4[Value]())
 			} else {
 				// We want to test that the Node4 reduces itself to
 				// A Leaf if its only child is a leaf
-				node.addChild(byte(i), newLeafNode(nil, nil))
+				node.addChild(byte(i), newLeafNode[Value](nil, nil))
 			}
 		}
 
@@ -169,10 +169,37 @@ func TestShrink(t *testing.T) {
 	}
 }
 
+// The terminal child must survive growing and shrinking of its node.
+func TestGrowAndShrinkKeepTerminal(t *testing.T) {
+	terminal := newLeafNode[Value](Key("a"), "a")
+	node := newNode4[Value]()
+	*node.terminal() = terminal
+
+	for i := 0; i < 49; i++ {
+		node.addChild(byte(i), newLeafNode[Value](Key{'a', byte(i)}, i))
+	}
+	assert.Equal(t, Node256, node.kind)
+	assert.Equal(t, terminal, *node.terminal())
+	assert.Equal(t, terminal, node.minimum())
+
+	for i := 0; i < 48; i++ {
+		node.RemoveChild(byte(i))
+	}
+	assert.Equal(t, Node4, node.kind)
+	assert.Equal(t, 1, node.node().size)
+	assert.Equal(t, terminal, *node.terminal())
+	assert.Equal(t, Key{'a', 48}, node.maximum().leaf().key)
+
+	// A Node4 collapses into its terminal child once the other children are gone.
+	node.RemoveChild(48)
+	assert.Equal(t, Leaf, node.kind)
+	assert.Equal(t, Key("a"), node.leaf().key)
+}
+
 func TestNewLeafNode(t *testing.T) {
 	key := []byte{'a', 'r', 't'}
 	value := "tree"
-	l := newLeafNode(key, value)
+	l := newLeafNode[Value](key, value)
 
 	if &l.leaf().key == &key {
 		t.Errorf("Address of key byte slices should not match.")
@@ -190,3 +217,33 @@ func TestNewLeafNode(t *testing.T) {
 		t.Errorf("Expected Leaf node to be of Leaf type")
 	}
 }
+
+// Children of all node types should be reachable by positions in ascending order of keys.
+func TestNextPrevChildForAllNodeTypes(t *testing.T) {
+	nodes := []*artNode[Value]{newNode4[Value](), newNode16[Value](), newNode48[Value](), newNode256[Value]()}
+
+	for _, n := range nodes {
+		// Keys are doubled, so they have to fit into a byte.
+		total := min(n.maxSize(), 128)
+		for i := total - 1; i >= 0; i-- {
+			n.addChild(byte(2*i), newLeafNode[Value]([]byte{byte(2 * i)}, 2*i))
+		}
+
+		var values []int
+		for pos, child := n.nextChild(0); child != nil; pos, child = n.nextChild(pos + 1) {
+			values = append(values, child.Value().(int))
+		}
+		assert.Len(t, values, total)
+		for i, value := range values {
+			assert.Equal(t, 2*i, value)
+		}
+
+		_, child := n.prevChild(n.lastPosition())
+		assert.Equal(t, 2*(total-1), child.Value())
+
+		pos, child := n.nextChild(n.childPosition(3))
+		assert.Equal(t, 4, child.Value())
+		_, child = n.prevChild(pos - 1)
+		assert.Equal(t, 2, child.Value())
+	}
+}
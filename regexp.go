@@ -0,0 +1,239 @@
+// Copyright © 2019, Oleksandr Krykovliuk <k33nice@gmail.com>.
+// Use of this source code is governed by the
+// MIT license that can be found in the LICENSE file.
+
+package art
+
+import (
+	"regexp"
+	"regexp/syntax"
+	"unicode/utf8"
+)
+
+// An automaton built from a regular expression, which is simulated as a Pike VM
+// over the runes of the path while descending the tree.
+// The accepted keys are confirmed by the expression itself.
+type regexpMatcher struct {
+	re   *regexp.Regexp
+	prog *syntax.Prog
+	// Matches have to start at the beginning of the key.
+	anchored bool
+	// The literal bytes every key of an anchored expression starts with.
+	prefix []byte
+}
+
+// The state of the automaton after consuming a path.
+type regexpState struct {
+	// Instructions waiting for the next rune, before following the empty transitions.
+	pcs []uint32
+	// The last consumed rune, or -1 at the beginning of the key.
+	prev rune
+	// The bytes of an incomplete UTF-8 sequence at the end of the path.
+	pending []byte
+	// The path already contains a match, so does every key below.
+	matched bool
+}
+
+// Calls the passed in callback for every leaf whose key matches the regular expression,
+// in key order. A key matches the same way as re.Match does, so unless the expression is anchored
+// a match can be anywhere in the key. Only the branches whose path can still lead to a match
+// are traversed, and the literal prefix of an anchored expression is looked up directly.
+// The expression is expected to be compiled with regexp.Compile,
+// the RegexpPOSIX option tells that it's compiled with regexp.CompilePOSIX instead.
+func (t *tree[V]) MatchRegexp(re *regexp.Regexp, callback Callback[V], options ...int) {
+	m := newRegexpMatcher(re, traverseOptions(options)&RegexpPOSIX != 0)
+	matchHelper[V, regexpState](t, t.root, m, 0, regexpState{prev: -1}, callback)
+}
+
+// Builds the automaton of the passed in regular expression, which is parsed with the same flags
+// as the regexp package uses for the syntax. Perl expressions anchored with ^ match at the beginning
+// of the key only, while POSIX ones match at the beginning of every line, so they can't be pruned by it.
+// The matcher has no automaton if the expression can't be parsed, so every key is checked.
+func newRegexpMatcher(re *regexp.Regexp, posix bool) *regexpMatcher {
+	m := &regexpMatcher{re: re}
+
+	flags := syntax.Perl
+	if posix {
+		flags = syntax.POSIX
+	}
+	parsed, err := syntax.Parse(re.String(), flags)
+	if err != nil {
+		return m
+	}
+	prog, err := syntax.Compile(parsed.Simplify())
+	if err != nil {
+		return m
+	}
+
+	m.prog = prog
+	if prog.StartCond()&syntax.EmptyBeginText != 0 {
+		m.anchored = true
+		m.prefix = regexpPrefix(prog)
+	}
+	return m
+}
+
+// Returns the literal bytes every match of the passed in program starts with.
+// The prefix stops at utf8.RuneError, since invalid bytes of a key are matched as it as well.
+func regexpPrefix(prog *syntax.Prog) []byte {
+	var prefix []byte
+	for pc := uint32(prog.Start); ; {
+		inst := &prog.Inst[pc]
+		switch inst.Op {
+		case syntax.InstNop, syntax.InstCapture, syntax.InstEmptyWidth:
+			// Assertions don't consume anything, they are checked while matching anyway.
+
+		case syntax.InstRune1, syntax.InstRune:
+			fold := inst.Op == syntax.InstRune && syntax.Flags(inst.Arg)&syntax.FoldCase != 0
+			if len(inst.Rune) != 1 || fold || inst.Rune[0] == utf8.RuneError {
+				return prefix
+			}
+			prefix = utf8.AppendRune(prefix, inst.Rune[0])
+
+		default:
+			return prefix
+		}
+		pc = inst.Out
+	}
+}
+
+// Returns the state after appending the passed in byte to the path,
+// and false if neither the new path nor any of its continuations can match.
+func (m *regexpMatcher) step(state regexpState, _ int, b byte) (regexpState, bool) {
+	next := m.feed(state, b)
+	return next, !m.dead(next)
+}
+
+// Reports whether the automaton matches the key, which is confirmed by the expression.
+func (m *regexpMatcher) accepts(state regexpState, key Key) bool {
+	return (m.prog == nil || m.acceptsEnd(state)) && m.re.Match(key)
+}
+
+// Every continuation of a path that contains a match is a candidate.
+func (m *regexpMatcher) acceptsRest(state regexpState) bool {
+	return m.prog == nil || state.matched
+}
+
+// Within the literal prefix there is only one child to follow.
+func (m *regexpMatcher) literals(_ regexpState, depth int) ([]byte, bool) {
+	if depth < len(m.prefix) {
+		return m.prefix[depth : depth+1], true
+	}
+	return nil, false
+}
+
+// Returns the state after appending the passed in byte to the path.
+// Runes are consumed once their UTF-8 sequences are complete,
+// and invalid bytes are consumed as utf8.RuneError the same way as the regexp package does.
+func (m *regexpMatcher) feed(state regexpState, b byte) regexpState {
+	if state.matched {
+		return state
+	}
+
+	// The pending bytes are shared between the siblings, so they are never appended in place.
+	pending := append(state.pending[:len(state.pending):len(state.pending)], b)
+	for len(pending) > 0 && utf8.FullRune(pending) && !state.matched {
+		r, size := utf8.DecodeRune(pending)
+		state = m.consume(state, r)
+		pending = pending[size:]
+	}
+	state.pending = pending
+	return state
+}
+
+// Returns the state after consuming the passed in rune.
+func (m *regexpMatcher) consume(state regexpState, r rune) regexpState {
+	closed, matched := m.closure(state, syntax.EmptyOpContext(state.prev, r))
+	if matched {
+		return regexpState{matched: true}
+	}
+
+	var pcs []uint32
+	for _, pc := range closed {
+		inst := &m.prog.Inst[pc]
+
+		var ok bool
+		switch inst.Op {
+		case syntax.InstRune:
+			ok = inst.MatchRune(r)
+		case syntax.InstRune1:
+			ok = r == inst.Rune[0]
+		case syntax.InstRuneAny:
+			ok = true
+		case syntax.InstRuneAnyNotNL:
+			ok = r != '\n'
+		}
+		if ok {
+			pcs = append(pcs, inst.Out)
+		}
+	}
+
+	return regexpState{pcs: pcs, prev: r}
+}
+
+// Follows the empty transitions from the waiting instructions of the passed in state
+// within the passed in context. Returns the reached instructions that consume runes,
+// and whether a match is reached.
+func (m *regexpMatcher) closure(state regexpState, context syntax.EmptyOp) ([]uint32, bool) {
+	pcs := state.pcs
+	// Unless the expression is anchored, a match may start at any position.
+	if !m.anchored || state.prev == -1 {
+		pcs = append(pcs[:len(pcs):len(pcs)], uint32(m.prog.Start))
+	}
+
+	visited := make([]bool, len(m.prog.Inst))
+	var closed []uint32
+	matched := false
+
+	var follow func(pc uint32)
+	follow = func(pc uint32) {
+		if visited[pc] || matched {
+			return
+		}
+		visited[pc] = true
+
+		inst := &m.prog.Inst[pc]
+		switch inst.Op {
+		case syntax.InstMatch:
+			matched = true
+		case syntax.InstAlt, syntax.InstAltMatch:
+			follow(inst.Out)
+			follow(inst.Arg)
+		case syntax.InstNop, syntax.InstCapture:
+			follow(inst.Out)
+		case syntax.InstEmptyWidth:
+			if syntax.EmptyOp(inst.Arg)&^context == 0 {
+				follow(inst.Out)
+			}
+		case syntax.InstRune, syntax.InstRune1, syntax.InstRuneAny, syntax.InstRuneAnyNotNL:
+			closed = append(closed, pc)
+		}
+	}
+
+	for _, pc := range pcs {
+		follow(pc)
+	}
+	return closed, matched
+}
+
+// Reports whether the automaton matches the key equal to the path of the passed in state.
+func (m *regexpMatcher) acceptsEnd(state regexpState) bool {
+	if state.matched {
+		return true
+	}
+
+	// The bytes of an incomplete sequence at the end of the key are invalid runes.
+	for range state.pending {
+		if state = m.consume(state, utf8.RuneError); state.matched {
+			return true
+		}
+	}
+
+	_, matched := m.closure(state, syntax.EmptyOpContext(state.prev, -1))
+	return matched
+}
+
+// Reports whether neither the path of the passed in state nor any of its continuations can match.
+func (m *regexpMatcher) dead(state regexpState) bool {
+	return !state.matched && m.anchored && len(state.pcs) == 0 && state.prev != -1
+}
@@ -0,0 +1,97 @@
+// Copyright © 2019, Oleksandr Krykovliuk <k33nice@gmail.com>.
+// Use of this source code is governed by the
+// MIT license that can be found in the LICENSE file.
+
+package art
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/k33nice/libart/internal/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func regexpKeys(tree *tree[Value], re *regexp.Regexp, options ...int) []string {
+	return searchKeys(func(callback Callback[Value]) {
+		tree.MatchRegexp(re, callback, options...)
+	})
+}
+
+// Returns the keys of the tree in key order that re.Match accepts.
+func expectedRegexpKeys(tree *tree[Value], re *regexp.Regexp) []string {
+	var keys []string
+	tree.Each(func(node Node[Value]) {
+		if re.Match(node.Key()) {
+			keys = append(keys, string(node.Key()))
+		}
+	}, TraverseLeaf)
+	return keys
+}
+
+// MatchRegexp should match keys the same way as re.Match does.
+func TestMatchRegexp(t *testing.T) {
+	tree := newArt[Value]()
+	keys := []string{
+		"", "a", "abc", "abcd", "xabc", "ab\nc", "topic.logs.app1", "topic.logs.app2", "topic.metrics.app1",
+		"TOPIC.logs", "x\ntopic.logs", "héllo", "h\xc3", "h\xc3x", "\xff", "a\xe2\x28", "a\xe2\x82", "foo bar", "foobar",
+	}
+	for _, key := range keys {
+		tree.Insert(Key(key), key)
+	}
+
+	patterns := []string{
+		``, `^$`, `^a`, `abc`, `^abc$`, `c$`, `^a.c`, `(?s)^ab.c`, `(?m)^c`, `^topic\.logs\.`, `^topic\.[a-z]+\.app1$`,
+		`(?i)^topic`, `app[0-9]`, `\bbar`, `o\Bb`, `^h.llo$`, `^h\x{FFFD}`, `\x{FFFD}$`, `^a\x{FFFD}\(`, `^(ab|xa)c`,
+		`^[^t]`, `l+o`, `^\z`, `\Atopic`,
+	}
+	for _, pattern := range patterns {
+		re := regexp.MustCompile(pattern)
+		assert.Equal(t, expectedRegexpKeys(tree, re), regexpKeys(tree, re), pattern)
+	}
+
+	for _, pattern := range []string{`^topic[.](logs|metrics)`, `^c`, `b$`, `^c$`, `[^a]c`, `a**`} {
+		re := regexp.MustCompilePOSIX(pattern)
+		assert.Equal(t, expectedRegexpKeys(tree, re), regexpKeys(tree, re, RegexpPOSIX), pattern)
+	}
+
+	assert.Empty(t, regexpKeys(newArt[Value](), regexp.MustCompile(``)))
+}
+
+// MatchRegexp over the words should agree with re.Match.
+func TestMatchRegexpManyWords(t *testing.T) {
+	tree := newArt[Value]()
+	words := test.LoadTestFile("test/data/words.txt")
+	for _, w := range words {
+		tree.Insert(w, w)
+	}
+
+	for _, pattern := range []string{`^ab.*ly$`, `^[xyz][aeiou]{2}`, `^(pre|post)fix`, `tion$`, `^Z`, `^zyzzyva$`, `qu[^e]z`} {
+		re := regexp.MustCompile(pattern)
+		assert.Equal(t, expectedRegexpKeys(tree, re), regexpKeys(tree, re), pattern)
+	}
+}
+
+// Anchored expressions should only visit the branches of their literal prefix.
+func TestMatchRegexpAnchoredPrefix(t *testing.T) {
+	tree := newArt[Value]()
+	words := test.LoadTestFile("test/data/words.txt")
+	for _, w := range words {
+		tree.Insert(w, w)
+	}
+
+	steps := func(re *regexp.Regexp, posix bool) int {
+		m := &countingMatcher[regexpState]{pathMatcher: newRegexpMatcher(re, posix)}
+		matchHelper[Value, regexpState](tree, tree.root, m, 0, regexpState{prev: -1}, func(Node[Value]) {})
+		return m.steps
+	}
+
+	for _, pattern := range []string{`^abandon`, `\Aabandon`, `^abandon(ed|ment)?$`} {
+		re := regexp.MustCompile(pattern)
+		assert.Equal(t, expectedRegexpKeys(tree, re), regexpKeys(tree, re), pattern)
+		assert.Less(t, steps(re, false), 100, pattern)
+	}
+
+	// The anchor of a POSIX expression matches at every line, so the words are scanned.
+	assert.Greater(t, steps(regexp.MustCompilePOSIX(`^abandon`), true), len(words))
+}
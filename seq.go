@@ -0,0 +1,47 @@
+// Copyright © 2019, Oleksandr Krykovliuk <k33nice@gmail.com>.
+// Use of this source code is governed by the
+// MIT license that can be found in the LICENSE file.
+
+package art
+
+import "iter"
+
+// Returns an iterator over all keys and values of the tree in key order.
+func (t *tree[V]) All() iter.Seq2[Key, V] {
+	return func(yield func(Key, V) bool) {
+		t.eachHelper(t.root, yieldLeaves(yield), leavesOnly(traverseOptions(nil)))
+	}
+}
+
+// Returns an iterator over all keys and values of the tree in descending key order.
+func (t *tree[V]) Backward() iter.Seq2[Key, V] {
+	return func(yield func(Key, V) bool) {
+		t.eachHelper(t.root, yieldLeaves(yield), leavesOnly(traverseOptions([]int{TraverseReverse})))
+	}
+}
+
+// Returns an iterator over the keys starting with the passed in prefix and their values.
+func (t *tree[V]) ScanPrefix(prefix Key, options ...int) iter.Seq2[Key, V] {
+	return func(yield func(Key, V) bool) {
+		t.eachHelper(t.prefixHelper(t.root, prefix, 0, nil), yieldLeaves(yield), leavesOnly(traverseOptions(options)))
+	}
+}
+
+// Returns an iterator over the keys between start and end and their values.
+// The bounds are treated the same way as in Range.
+func (t *tree[V]) ScanRange(start, end Key, options ...int) iter.Seq2[Key, V] {
+	return func(yield func(Key, V) bool) {
+		t.rangeHelper(t.root, start, end, 0, start != nil, end != nil, leavesOnly(traverseOptions(options)), yieldLeaves(yield))
+	}
+}
+
+// Adapts the yield function of an iterator to be used as a WalkFunc for leaves.
+// The traversal stops as soon as the consumer breaks out of the loop.
+func yieldLeaves[V any](yield func(Key, V) bool) WalkFunc[V] {
+	return func(node Node[V]) error {
+		if !yield(node.Key(), node.Value()) {
+			return SkipAll
+		}
+		return nil
+	}
+}
@@ -0,0 +1,128 @@
+// Copyright © 2019, Oleksandr Krykovliuk <k33nice@gmail.com>.
+// Use of this source code is governed by the
+// MIT license that can be found in the LICENSE file.
+
+package art
+
+import (
+	"testing"
+
+	"github.com/k33nice/libart/internal/test"
+	"github.com/stretchr/testify/assert"
+)
+
+// Ranging over All and Backward should return all keys in both orders.
+func TestAllAndBackward(t *testing.T) {
+	tree := newArt[Value]()
+
+	words := test.LoadTestFile("test/data/words.txt")
+	for _, w := range words {
+		tree.Insert(w, w)
+	}
+
+	var forward []string
+	for key, value := range tree.All() {
+		assert.Equal(t, key, value)
+		forward = append(forward, string(key))
+	}
+
+	var backward []string
+	for key := range tree.Backward() {
+		backward = append(backward, string(key))
+	}
+
+	var expected []string
+	tree.Each(func(node Node[Value]) {
+		expected = append(expected, string(node.Key()))
+	}, TraverseLeaf)
+
+	assert.Equal(t, expected, forward)
+	assert.Len(t, backward, len(expected))
+	for i := range backward {
+		assert.Equal(t, expected[len(expected)-1-i], backward[i])
+	}
+}
+
+// Breaking out of the loop should stop every kind of scan.
+func TestScanBreak(t *testing.T) {
+	tree := newArt[Value]()
+
+	words := test.LoadTestFile("test/data/words.txt")
+	for _, w := range words {
+		tree.Insert(w, w)
+	}
+
+	scans := map[string]func() []string{
+		"all": func() (keys []string) {
+			for key := range tree.All() {
+				if keys = append(keys, string(key)); len(keys) == 3 {
+					break
+				}
+			}
+			return keys
+		},
+		"backward": func() (keys []string) {
+			for key := range tree.Backward() {
+				if keys = append(keys, string(key)); len(keys) == 3 {
+					break
+				}
+			}
+			return keys
+		},
+		"prefix": func() (keys []string) {
+			for key := range tree.ScanPrefix(Key("inter")) {
+				if keys = append(keys, string(key)); len(keys) == 3 {
+					break
+				}
+			}
+			return keys
+		},
+		"range": func() (keys []string) {
+			for key := range tree.ScanRange(Key("b"), nil) {
+				if keys = append(keys, string(key)); len(keys) == 3 {
+					break
+				}
+			}
+			return keys
+		},
+		"reverse range": func() (keys []string) {
+			for key := range tree.ScanRange(nil, Key("b"), TraverseReverse) {
+				if keys = append(keys, string(key)); len(keys) == 3 {
+					break
+				}
+			}
+			return keys
+		},
+	}
+
+	for name, scan := range scans {
+		assert.Len(t, scan(), 3, name)
+	}
+}
+
+// Prefix and range scans should return the same keys as the callback based traversals.
+func TestScanPrefixAndRange(t *testing.T) {
+	tree := newArt[Value]()
+	for _, w := range []string{"app", "api", "apple", "application", "apply", "banana"} {
+		tree.Insert(Key(w), w)
+	}
+
+	var keys []string
+	for key, value := range tree.ScanPrefix(Key("appl"), TraverseReverse) {
+		assert.Equal(t, string(key), value)
+		keys = append(keys, string(key))
+	}
+	assert.Equal(t, []string{"apply", "application", "apple"}, keys)
+
+	keys = nil
+	for key := range tree.ScanRange(Key("api"), Key("apply"), RangeExcludeStart) {
+		keys = append(keys, string(key))
+	}
+	assert.Equal(t, []string{"app", "apple", "application"}, keys)
+
+	keys = nil
+	for key := range newArt[Value]().ScanPrefix(nil) {
+		keys = append(keys, string(key))
+	}
+	assert.Empty(t, keys)
+}
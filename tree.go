@@ -4,63 +4,188 @@
 
 package art
 
-type tree struct {
-	root *artNode
+import (
+	"bytes"
+	"math/rand"
+)
+
+type tree[V any] struct {
+	root *artNode[V]
 	size int64
 }
 
-func newArt() *tree {
-	return &tree{root: nil, size: 0}
+func newArt[V any]() *tree[V] {
+	return &tree[V]{root: nil, size: 0}
+}
+
+// Returns the value that is indexed by the passed in key, or the zero value if not found.
+func (t *tree[V]) Search(key Key) V {
+	value, _ := t.searchHelper(t.root, key, 0)
+	return value
 }
 
-// Returns the node that contains the passed in key, or nil if not found.
-func (t *tree) Search(key Key) Value {
+// Returns the value that is indexed by the passed in key and whether the key was found.
+func (t *tree[V]) Lookup(key Key) (V, bool) {
 	return t.searchHelper(t.root, key, 0)
 }
 
 // Recursive search helper function that traverses the tree.
-// Returns the node that contains the passed in key, or nil if not found.
-func (t *tree) searchHelper(current *artNode, key []byte, depth int) interface{} {
+// Returns the value of the leaf that contains the passed in key and true,
+// or the zero value and false if not found.
+func (t *tree[V]) searchHelper(current *artNode[V], key []byte, depth int) (V, bool) {
+	var zero V
+
 	// While we have nodes to search
 	for current != nil {
 		// Check if the current is a match
 		if current.isLeaf() {
 			if current.isMatch(key) {
-				return current.leaf().value
+				return current.leaf().value, true
 			}
 
 			// Bail if no match
-			return nil
+			return zero, false
 		}
 
 		// Check if our key mismatches the current compressed path
 		if current.prefixMismatch(key, depth) != current.node().prefixLen {
 			// Bail if there's a mismatch during traversal.
-			return nil
+			return zero, false
 		}
 		// Otherwise, increase depth accordingly.
 		depth += current.node().prefixLen
 
 		// Find the next node at the specified index, and update depth.
-		var keyChar byte
-		if depth < 0 || depth >= len(key) {
-			keyChar = byte(0)
-		} else {
-			keyChar = key[depth]
-		}
-		current = *(current.findChild(keyChar))
+		current = *(current.findChildAt(key, depth))
 		depth++
 	}
 
-	return nil
+	return zero, false
+}
+
+// Returns the longest stored key that is a prefix of the passed in key along with its value.
+func (t *tree[V]) LongestPrefix(key Key) (Key, V, bool) {
+	var match *artNode[V]
+	t.prefixesOfHelper(key, func(node *artNode[V]) {
+		match = node
+	})
+	return leafEntry(match)
+}
+
+// Calls the passed in callback for every stored key that is a prefix of the passed in key,
+// from the shortest to the longest one.
+func (t *tree[V]) EachPrefixOf(key Key, callback Callback[V]) {
+	t.prefixesOfHelper(key, func(node *artNode[V]) {
+		callback(node)
+	})
+}
+
+// Helper that follows the search path of the passed in key
+// and reports every leaf met on the way whose key is a prefix of the passed in key.
+func (t *tree[V]) prefixesOfHelper(key []byte, callback func(*artNode[V])) {
+	current := t.root
+	depth := 0
+	for current != nil {
+		if current.isLeaf() {
+			if bytes.HasPrefix(key, current.leaf().key) {
+				callback(current)
+			}
+			return
+		}
+
+		// Bail if the key diverges from the compressed path.
+		if current.prefixMismatch(key, depth) != current.node().prefixLen {
+			return
+		}
+		depth += current.node().prefixLen
+
+		// The terminal child holds the key that is equal to the path so far.
+		if terminal := *current.terminal(); terminal != nil {
+			callback(terminal)
+		}
+		if depth >= len(key) {
+			return
+		}
+
+		current = *(current.findChild(key[depth]))
+		depth++
+	}
 }
 
 // Inserts the passed in value that is indexed by the passed in key into the ArtTree.
-func (t *tree) Insert(key Key, value Value) {
-	t.insertHelper(&t.root, key, value, 0)
+// If the key is already present its value is replaced, and the previous value is returned.
+func (t *tree[V]) Insert(key Key, value V) (V, bool) {
+	var old V
+	updated := false
+	t.updateHelper(&t.root, key, 0, func(stored V, exists bool) (V, Action) {
+		old, updated = stored, exists
+		return value, UpdateStore
+	})
+	return old, updated
+}
+
+// Inserts the passed in value only if the key is not present in the ArtTree yet.
+// Otherwise the stored value is left untouched and returned.
+func (t *tree[V]) InsertIfAbsent(key Key, value V) (V, bool) {
+	old, found := t.GetOrInsert(key, value)
+	if !found {
+		var zero V
+		return zero, true
+	}
+	return old, false
+}
+
+// Returns the value stored by the passed in key if it's present,
+// otherwise inserts the passed in value and returns it.
+func (t *tree[V]) GetOrInsert(key Key, value V) (V, bool) {
+	found := false
+	actual, _, _ := t.updateHelper(&t.root, key, 0, func(stored V, exists bool) (V, Action) {
+		if found = exists; found {
+			return stored, UpdateKeep
+		}
+		return value, UpdateStore
+	})
+	return actual, found
+}
+
+// Calls the update function with the value stored by the passed in key
+// and stores or removes the key according to the returned action.
+// Returns the value stored by the key afterwards and whether the key is present.
+func (t *tree[V]) Update(key Key, fn UpdateFunc[V]) (V, bool) {
+	value, present, _ := t.updateHelper(&t.root, key, 0, fn)
+	return value, present
 }
 
-// Recursive helper function that traverses the tree until an insertion point is found.
+// Stores the new value by the passed in key if the stored value is equal to the old one.
+func (t *tree[V]) CompareAndSwap(key Key, old, new V, equal func(a, b V) bool) bool {
+	equal = equalFunc(equal)
+	swapped := false
+	t.updateHelper(&t.root, key, 0, func(value V, exists bool) (V, Action) {
+		if !exists || !equal(value, old) {
+			return value, UpdateKeep
+		}
+		swapped = true
+		return new, UpdateStore
+	})
+	return swapped
+}
+
+// Removes the passed in key if the stored value is equal to the old one.
+func (t *tree[V]) CompareAndDelete(key Key, old V, equal func(a, b V) bool) bool {
+	equal = equalFunc(equal)
+	deleted := false
+	t.updateHelper(&t.root, key, 0, func(value V, exists bool) (V, Action) {
+		if !exists || !equal(value, old) {
+			return value, UpdateKeep
+		}
+		deleted = true
+		return value, UpdateDelete
+	})
+	return deleted
+}
+
+// Recursive helper function that traverses the tree until the key or an insertion point is found,
+// and calls the update function once. All of the insertions and removals are built on it.
 // There are four methods of insertion:
 //
 // If the current node is null, a new node is created with the passed in key-value pair
@@ -75,14 +200,24 @@ func (t *tree) Insert(key Key, value Value) {
 //
 // If there is no child at the specified key at the current depth of traversal, a new leaf node
 // is created and inserted at this position.
-func (t *tree) insertHelper(currentRef **artNode, key []byte, value interface{}, depth int) {
+//
+// A leaf with the same key is updated in place, and removed by its parent or by the tree itself if it's the root.
+// Returns the value stored by the key afterwards, whether the key is present,
+// and the change of the number of leaves in the subtree.
+func (t *tree[V]) updateHelper(currentRef **artNode[V], key []byte, depth int, fn UpdateFunc[V]) (V, bool, int) {
+	var zero V
+
 	// @spec: Usually, the leaf can
 	//        simply be inserted into an existing inner node, after growing
 	//        it if necessary.
 	if *currentRef == nil {
-		*currentRef = newLeafNode(key, value)
+		value, action := fn(zero, false)
+		if action != UpdateStore {
+			return zero, false, 0
+		}
+		*currentRef = newLeafNode[V](key, value)
 		t.size++
-		return
+		return value, true, 1
 	}
 	current := *currentRef
 
@@ -90,219 +225,799 @@ func (t *tree) insertHelper(currentRef **artNode, key []byte, value interface{},
 	//        an existing leaf is encountered, it is replaced by a new
 	//        inner node storing the existing and the new leaf
 	if current.isLeaf() {
-
-		// TODO Determine if we should overwrite keys if they are attempted to overwritten.
-		//      Currently, we bail if the key matches.
+		// Only the root leaf is matched here, the other leaves are handled by their parents.
 		if current.isMatch(key) {
-			return
+			value, remove := updateLeaf(current, fn)
+			if !remove {
+				return value, true, 0
+			}
+			*currentRef = nil
+			t.size--
+			return zero, false, -1
 		}
 
-		// Create a new Inner Node to contain the new Leaf and the current node.
-		newNode4 := newNode4()
-		newLeafNode := newLeafNode(key, value)
-
-		// Determine the longest common prefix between our current node and the key
-		limit := current.longestCommonPrefix(newLeafNode, depth)
-
-		newNode4.node().prefixLen = limit
+		value, action := fn(zero, false)
+		if action != UpdateStore {
+			return zero, false, 0
+		}
+		splitLeaf(currentRef, newLeafNode[V](key, value), depth)
+		t.size++
+		return value, true, 1
+	}
 
-		memcpy(newNode4.node().prefix[:], key[depth:], min(newNode4.node().prefixLen, maxPrefixLen))
+	// @spec: Another special case occurs if the key of the new leaf
+	//        differs from a compressed path: A new inner node is created
+	//        above the current node and the compressed paths are adjusted accordingly.
+	if current.node().prefixLen != 0 {
+		if mismatch := current.prefixMismatch(key, depth); mismatch != current.node().prefixLen {
+			value, action := fn(zero, false)
+			if action != UpdateStore {
+				return zero, false, 0
+			}
+			splitPrefix(currentRef, newLeafNode[V](key, value), depth, mismatch)
+			t.size++
+			return value, true, 1
+		}
+		depth += current.node().prefixLen
+	}
 
-		*currentRef = newNode4
+	next := current.findChildAt(key, depth)
 
-		// Add both children to the new Inner Node
-		if depth+newNode4.node().prefixLen < 0 || depth+newNode4.node().prefixLen >= len(current.leaf().key) {
-			newNode4.addChild(0, current)
-		} else {
-			newNode4.addChild(current.leaf().key[depth+newNode4.node().prefixLen], current)
+	// The matching leaf is handled here, since removing it requires its parent.
+	if *next != nil && (*next).isLeaf() && (*next).isMatch(key) {
+		value, remove := updateLeaf(*next, fn)
+		if !remove {
+			return value, true, 0
 		}
 
-		if depth+newNode4.node().prefixLen < 0 || depth+newNode4.node().prefixLen >= len(key) {
-			newNode4.addChild(0, newLeafNode)
+		if depth >= len(key) {
+			current.unlink(1, true)
 		} else {
-			newNode4.addChild(key[depth+newNode4.node().prefixLen], newLeafNode)
+			current.unlink(1, false, key[depth])
 		}
+		t.size--
+		return zero, false, -1
+	}
 
-		t.size++
-		return
+	if *next != nil {
+		value, present, delta := t.updateHelper(next, key, depth+1, fn)
+		current.node().count += delta
+		return value, present, delta
 	}
 
-	// @spec: Another special case occurs if the key of the new leaf
-	//        differs from a compressed path: A new inner node is created
-	//        above the current node and the compressed paths are adjusted accordingly.
+	value, action := fn(zero, false)
+	if action != UpdateStore {
+		return zero, false, 0
+	}
+	current.addLeafAt(newLeafNode[V](key, value), depth)
+	current.node().count++
+	t.size++
+	return value, true, 1
+}
+
+// Calls the update function with the value of the passed in existing leaf and stores the returned value
+// if requested. Removing the leaf is left to the caller, which knows how to unlink it.
+// Returns the value of the leaf afterwards and whether it has to be removed.
+func updateLeaf[V any](leaf *artNode[V], fn UpdateFunc[V]) (V, bool) {
+	value, action := fn(leaf.leaf().value, true)
+	switch action {
+	case UpdateStore:
+		leaf.leaf().value = value
+	case UpdateDelete:
+		var zero V
+		return zero, true
+	}
+	return leaf.leaf().value, false
+}
+
+// Returns the passed in equality function, or the one comparing the values with == if it's nil.
+func equalFunc[V any](equal func(a, b V) bool) func(a, b V) bool {
+	if equal != nil {
+		return equal
+	}
+	return func(a, b V) bool {
+		return any(a) == any(b)
+	}
+}
+
+// Replaces the leaf at the passed in reference with a new inner node of type Node4
+// that contains both the leaf and the passed in new leaf.
+func splitLeaf[V any](currentRef **artNode[V], newLeaf *artNode[V], depth int) {
+	current := *currentRef
+
+	// Create a new Inner Node to contain the new Leaf and the current node.
+	newNode4 := newNode4[V]()
+
+	// Determine the longest common prefix between our current node and the key
+	limit := current.longestCommonPrefix(newLeaf, depth)
+
+	newNode4.node().prefixLen = limit
+
+	memcpy(newNode4.node().prefix[:], newLeaf.leaf().key[depth:], min(newNode4.node().prefixLen, maxPrefixLen))
+
+	*currentRef = newNode4
+
+	// Add both children to the new Inner Node.
+	// At most one of the keys can end at the new node and becomes its terminal child.
+	newNode4.addLeafAt(current, depth+limit)
+	newNode4.addLeafAt(newLeaf, depth+limit)
+	newNode4.node().count = 2
+}
+
+// Creates a new inner node of type Node4 above the inner node at the passed in reference,
+// whose compressed path differs from the key of the new leaf at the mismatch position.
+// The new inner node contains the current node and the new leaf.
+func splitPrefix[V any](currentRef **artNode[V], newLeaf *artNode[V], depth, mismatch int) {
+	current := *currentRef
 	node := current.node()
-	if node.prefixLen != 0 {
-
-		mismatch := current.prefixMismatch(key, depth)
-
-		// If the key differs from the compressed path
-		if mismatch != node.prefixLen {
-
-			// Create a new Inner Node that will contain the current node
-			// and the desired insertion key
-			newNode4 := newNode4()
-			*currentRef = newNode4
-			newNode4.node().prefixLen = mismatch
-
-			// Copy the mismatched prefix into the new inner node.
-			memcpy(newNode4.node().prefix[:], node.prefix[:], mismatch)
-
-			// Adjust prefixes so they fit underneath the new inner node
-			if node.prefixLen < maxPrefixLen {
-				newNode4.addChild(node.prefix[mismatch], current)
-				node.prefixLen -= (mismatch + 1)
-				memmove(node.prefix[:], node.prefix[mismatch+1:], min(node.prefixLen, maxPrefixLen))
-			} else {
-				node.prefixLen -= (mismatch + 1)
-				minKey := current.minimum().leaf().key
-				newNode4.addChild(minKey[depth+mismatch], current)
-				memmove(node.prefix[:], minKey[depth+mismatch+1:], min(node.prefixLen, maxPrefixLen))
-			}
 
-			// Attach the desired insertion key
-			newLeafNode := newLeafNode(key, value)
-			newNode4.addChild(key[depth+mismatch], newLeafNode)
+	// Create a new Inner Node that will contain the current node
+	// and the desired insertion key
+	newNode4 := newNode4[V]()
+	*currentRef = newNode4
+	newNode4.node().prefixLen = mismatch
 
-			t.size++
-			return
-		}
+	// Copy the mismatched prefix into the new inner node.
+	memcpy(newNode4.node().prefix[:], node.prefix[:], mismatch)
 
-		depth += node.prefixLen
+	// Adjust prefixes so they fit underneath the new inner node
+	if node.prefixLen < maxPrefixLen {
+		newNode4.addChild(node.prefix[mismatch], current)
+		node.prefixLen -= (mismatch + 1)
+		memmove(node.prefix[:], node.prefix[mismatch+1:], min(node.prefixLen, maxPrefixLen))
+	} else {
+		node.prefixLen -= (mismatch + 1)
+		minKey := current.minimum().leaf().key
+		newNode4.addChild(minKey[depth+mismatch], current)
+		memmove(node.prefix[:], minKey[depth+mismatch+1:], min(node.prefixLen, maxPrefixLen))
 	}
 
-	// Find the next child
-	next := current.findChild(key[depth])
+	// Attach the desired insertion key
+	newNode4.addLeafAt(newLeaf, depth+mismatch)
+	newNode4.node().count = current.leafCount() + 1
+}
 
-	// If we found a child that matches the key at the current depth
-	if *next != nil {
-		// Recurse, and keep looking for an insertion point
-		t.insertHelper(next, key, value, depth+1)
+// Delete the child that is accessed by the passed in key.
+// Returns the value of the removed child and whether it was found.
+func (t *tree[V]) Delete(key []byte) (V, bool) {
+	var old V
+	deleted := false
+	t.updateHelper(&t.root, key, 0, func(stored V, exists bool) (V, Action) {
+		old, deleted = stored, exists
+		return stored, UpdateDelete
+	})
+	return old, deleted
+}
+
+// Removes every key that starts with the passed in prefix by unlinking the subtree covering them.
+// Returns the number of removed keys.
+func (t *tree[V]) DeletePrefix(prefix Key) int {
+	var parents []*artNode[V]
+	var keys []byte
+	covering := t.prefixHelper(t.root, prefix, 0, func(parent *artNode[V], key byte) {
+		parents = append(parents, parent)
+		keys = append(keys, key)
+	})
+	if covering == nil {
+		return 0
+	}
+
+	removed := covering.leafCount()
+	if len(parents) == 0 {
+		t.root = nil
 	} else {
-		// Otherwise, Add the child at the current position.
-		current.addChild(key[depth], newLeafNode(key, value))
-		t.size++
+		last := len(parents) - 1
+		for _, parent := range parents[:last] {
+			parent.node().count -= removed
+		}
+		parents[last].unlink(removed, false, keys[last])
 	}
+
+	t.size -= int64(removed)
+	return removed
 }
 
-// Delete the child that is accessed by the passed in key.
-func (t *tree) Delete(key []byte) bool {
-	return t.removeHelper(&t.root, key, 0)
+// Removes every key between start and end by unlinking the subtrees that are fully inside of the range.
+// The bounds are treated the same way as in Range, so a nil bound is open, unlike an empty one.
+// Returns the number of removed keys.
+func (t *tree[V]) DeleteRange(start, end Key, options ...int) int {
+	removed, unlink := t.coverRangeHelper(t.root, start, end, 0, start != nil, end != nil, traverseOptions(options), true)
+	if unlink {
+		t.root = nil
+	}
+	t.size -= int64(removed)
+	return removed
 }
 
-// Recursive helper for Removing child nodes.
-// There are two methods for removal:
-//
-// If the current node is a leaf and matches the specified key, remove it.
-//
-// If the next child at the specifed key and depth matches,
-// the current node shall remove it accordingly.
-func (t *tree) removeHelper(currentRef **artNode, key []byte, depth int) bool {
-	// Bail early if we are at a nil node.
-	if t == nil || *currentRef == nil || len(key) == 0 {
-		return false
+// Recursive helper for DeleteRange and Count that descends the tree the same way as rangeHelper does,
+// but only along the paths of the bounds, since the subtrees between them are fully inside of the range.
+// Returns the number of keys within the range, and whether the whole subtree of the current node
+// is inside of it. If remove is set, the children inside of the range are unlinked,
+// while the current node is left to be unlinked by the caller.
+func (t *tree[V]) coverRangeHelper(current *artNode[V], start, end []byte, depth int, checkStart, checkEnd bool, opts int, remove bool) (int, bool) {
+	if current == nil {
+		return 0, false
 	}
 
-	current := *currentRef
-	// If the current node matches, remove it.
-	if current.isLeaf() {
-		if current.isMatch(key) {
-			*currentRef = nil
-			t.size--
+	depth, checkStart, checkEnd, ok := clipRange(current, start, end, depth, checkStart, checkEnd, opts)
+	if !ok {
+		return 0, false
+	}
+
+	// The whole subtree is inside of the range.
+	if current.isLeaf() || !checkStart && !checkEnd {
+		return current.leafCount(), true
+	}
+
+	startBelow := checkStart && depth < len(start)
+	endBelow := checkEnd && depth < len(end)
+
+	covered := 0
+	coverTerminal := false
+	if terminal := *current.terminal(); terminal != nil && !startBelow {
+		if n, whole := t.coverRangeHelper(terminal, start, end, depth, checkStart, checkEnd, opts, remove); whole {
+			covered += n
+			coverTerminal = true
+		}
+	}
+
+	// The children can't be unlinked while iterating over them, so their keys are collected first.
+	var unlinked []byte
+	if !checkEnd || endBelow {
+		lo, hi := byte(0), byte(255)
+		if startBelow {
+			lo = start[depth]
+		}
+		if endBelow {
+			hi = end[depth]
+		}
+
+		current.eachChildBetween(lo, hi, false, func(key byte, child *artNode[V]) bool {
+			n, whole := t.coverRangeHelper(child, start, end, depth+1, startBelow && key == lo, endBelow && key == hi, opts, remove)
+			covered += n
+			if whole {
+				unlinked = append(unlinked, key)
+			}
 			return true
+		})
+	}
+
+	if covered == 0 || covered == current.leafCount() {
+		return covered, covered != 0
+	}
+	if !remove {
+		return covered, false
+	}
+
+	current.unlink(covered, coverTerminal, unlinked...)
+	return covered, false
+}
+
+// Returns the smallest key in the tree along with its value.
+func (t *tree[V]) Minimum() (Key, V, bool) {
+	if t.root == nil {
+		var zero V
+		return nil, zero, false
+	}
+	leaf := t.root.minimum().leaf()
+	return leaf.key, leaf.value, true
+}
+
+// Returns the largest key in the tree along with its value.
+func (t *tree[V]) Maximum() (Key, V, bool) {
+	if t.root == nil {
+		var zero V
+		return nil, zero, false
+	}
+	leaf := t.root.maximum().leaf()
+	return leaf.key, leaf.value, true
+}
+
+// Removes the smallest key from the tree and returns it along with its value.
+func (t *tree[V]) PopMin() (Key, V, bool) {
+	key, _, ok := t.Minimum()
+	if !ok {
+		var zero V
+		return nil, zero, false
+	}
+	value, _ := t.Delete(key)
+	return key, value, true
+}
+
+// Removes the largest key from the tree and returns it along with its value.
+func (t *tree[V]) PopMax() (Key, V, bool) {
+	key, _, ok := t.Maximum()
+	if !ok {
+		var zero V
+		return nil, zero, false
+	}
+	value, _ := t.Delete(key)
+	return key, value, true
+}
+
+// Returns the greatest key that is less than or equal to the passed in key along with its value.
+func (t *tree[V]) Floor(key Key) (Key, V, bool) {
+	return leafEntry(t.floorHelper(key, false))
+}
+
+// Returns the smallest key that is greater than or equal to the passed in key along with its value.
+func (t *tree[V]) Ceiling(key Key) (Key, V, bool) {
+	return leafEntry(t.ceilingHelper(key, false))
+}
+
+// Returns the greatest key that is less than the passed in key along with its value.
+func (t *tree[V]) Predecessor(key Key) (Key, V, bool) {
+	return leafEntry(t.floorHelper(key, true))
+}
+
+// Returns the smallest key that is greater than the passed in key along with its value.
+func (t *tree[V]) Successor(key Key) (Key, V, bool) {
+	return leafEntry(t.ceilingHelper(key, true))
+}
+
+// Helper that descends along the passed in key and returns the leaf with the greatest key
+// that is less than the passed in key, or equal to it unless strict is set.
+// On the way down it remembers the closest subtree to the left of the path,
+// whose maximum is the answer once the path leaves the tree.
+func (t *tree[V]) floorHelper(key []byte, strict bool) *artNode[V] {
+	var prev *artNode[V]
+
+	current := t.root
+	depth := 0
+	for current != nil {
+		if current.isLeaf() {
+			if c := bytes.Compare(current.leaf().key, key); c < 0 || c == 0 && !strict {
+				return current
+			}
+			return prev.maximum()
+		}
+
+		if current.node().prefixLen != 0 {
+			switch comparePath(current.fullPrefix(depth), key, depth) {
+			case -1:
+				return current.maximum()
+			case 1:
+				return prev.maximum()
+			}
+			depth += current.node().prefixLen
+		}
+
+		// The path is equal to the key, so only the terminal child isn't greater than it.
+		if depth >= len(key) {
+			if terminal := *current.terminal(); terminal != nil && !strict {
+				return terminal
+			}
+			return prev.maximum()
 		}
+
+		// The terminal child and the children with smaller keys are to the left of the path.
+		if _, sibling := current.prevChild(current.childPosition(key[depth]) - 1); sibling != nil {
+			prev = sibling
+		}
+
+		current = *(current.findChild(key[depth]))
+		depth++
 	}
 
-	// If the current node contains a prefix length
-	if current.node().prefixLen != 0 {
+	return prev.maximum()
+}
 
-		// Bail out if we encounter a mismatch
-		mismatch := current.prefixMismatch(key, depth)
-		if mismatch != current.node().prefixLen {
-			return false
+// Helper that descends along the passed in key and returns the leaf with the smallest key
+// that is greater than the passed in key, or equal to it unless strict is set.
+// On the way down it remembers the closest subtree to the right of the path,
+// whose minimum is the answer once the path leaves the tree.
+func (t *tree[V]) ceilingHelper(key []byte, strict bool) *artNode[V] {
+	var next *artNode[V]
+
+	current := t.root
+	depth := 0
+	for current != nil {
+		if current.isLeaf() {
+			if c := bytes.Compare(current.leaf().key, key); c > 0 || c == 0 && !strict {
+				return current
+			}
+			return next.minimum()
 		}
 
-		// Increase traversal depth
-		depth += current.node().prefixLen
+		if current.node().prefixLen != 0 {
+			switch comparePath(current.fullPrefix(depth), key, depth) {
+			case 1:
+				return current.minimum()
+			case -1:
+				return next.minimum()
+			}
+			depth += current.node().prefixLen
+		}
+
+		// The path is equal to the key, so every child except the terminal one is greater than it.
+		if depth >= len(key) {
+			if terminal := *current.terminal(); terminal != nil && !strict {
+				return terminal
+			}
+			if _, child := current.nextChild(1); child != nil {
+				return child.minimum()
+			}
+			return next.minimum()
+		}
+
+		// The children with greater keys are to the right of the path.
+		pos := current.childPosition(key[depth])
+		child := *(current.findChild(key[depth]))
+		if child != nil {
+			pos++
+		}
+		if _, sibling := current.nextChild(pos); sibling != nil {
+			next = sibling
+		}
+
+		current = child
+		depth++
 	}
 
-	// Find the next child
-	var keyChar byte
-	if depth < 0 || depth >= len(key) {
-		keyChar = byte(0)
-	} else {
-		keyChar = key[depth]
+	return next.minimum()
+}
+
+// Returns the number of keys in the tree that are less than the passed in key.
+func (t *tree[V]) Rank(key Key) int {
+	return t.rankHelper(key, false)
+}
+
+// Returns the key at the passed in index in key order along with its value,
+// or false if the index is out of range.
+func (t *tree[V]) Select(index int) (Key, V, bool) {
+	if index < 0 || index >= t.Size() {
+		return leafEntry[V](nil)
 	}
-	next := current.findChild(keyChar)
+	return leafEntry(selectHelper(t.root, index))
+}
 
-	// Let the Inner Node handle the removal logic if the child is a match
-	if *next != nil && (*next).isLeaf() && (*next).isMatch(key) {
-		current.RemoveChild(keyChar)
-		t.size--
-		return true
+// Returns a uniformly chosen random key along with its value, or false if the tree is empty.
+func (t *tree[V]) RandomKey(rng *rand.Rand) (Key, V, bool) {
+	return t.RandomKeyWithPrefix(nil, rng)
+}
+
+// Returns a uniformly chosen random key that starts with the passed in prefix along with its value,
+// or false if there are no such keys.
+func (t *tree[V]) RandomKeyWithPrefix(prefix Key, rng *rand.Rand) (Key, V, bool) {
+	current := t.prefixHelper(t.root, prefix, 0, nil)
+	if current == nil {
+		return leafEntry[V](nil)
 	}
-	return t.removeHelper(next, key, depth+1)
+	return leafEntry(selectHelper(current, rng.Intn(current.leafCount())))
+}
+
+// Helper that returns the leaf at the passed in index in key order within the subtree of the current node.
+// Children are weighted by the counts of their subtrees.
+func selectHelper[V any](current *artNode[V], index int) *artNode[V] {
+	for current != nil && !current.isLeaf() {
+		// Skip the children whose subtrees are entirely before the index.
+		pos, child := current.nextChild(0)
+		for child != nil && index >= child.leafCount() {
+			index -= child.leafCount()
+			pos, child = current.nextChild(pos + 1)
+		}
+		current = child
+	}
+	return current
+}
+
+// Returns the number of keys between start and end.
+// The bounds are treated the same way as in Range.
+func (t *tree[V]) Count(start, end Key, options ...int) int {
+	count, _ := t.coverRangeHelper(t.root, start, end, 0, start != nil, end != nil, traverseOptions(options), false)
+	return count
+}
+
+// Returns the number of keys that start with the passed in prefix.
+func (t *tree[V]) CountPrefix(prefix Key) int {
+	return t.prefixHelper(t.root, prefix, 0, nil).leafCount()
+}
+
+// Helper that descends along the passed in key and sums up the counts of the subtrees
+// to the left of the path. Returns the number of keys less than the passed in key,
+// or less than or equal to it if inclusive is set.
+func (t *tree[V]) rankHelper(key []byte, inclusive bool) int {
+	rank := 0
+
+	current := t.root
+	depth := 0
+	for current != nil {
+		if current.isLeaf() {
+			if c := bytes.Compare(current.leaf().key, key); c < 0 || c == 0 && inclusive {
+				rank++
+			}
+			return rank
+		}
+
+		if current.node().prefixLen != 0 {
+			switch comparePath(current.fullPrefix(depth), key, depth) {
+			case -1:
+				return rank + current.leafCount()
+			case 1:
+				return rank
+			}
+			depth += current.node().prefixLen
+		}
+
+		// The path is equal to the key, so only the terminal child might be counted.
+		if depth >= len(key) {
+			if inclusive && *current.terminal() != nil {
+				rank++
+			}
+			return rank
+		}
+
+		rank += current.countBefore(current.childPosition(key[depth]))
+		current = *(current.findChild(key[depth]))
+		depth++
+	}
+
+	return rank
 }
 
 // Convenience method for EachPreorder
-func (t *tree) Each(callback Callback, opts ...int) {
-	t.eachHelper(t.root, callback)
+func (t *tree[V]) Each(callback Callback[V], options ...int) {
+	t.eachHelper(t.root, walkCallback(callback), traverseOptions(options))
+}
+
+// Iterates over all nodes in the tree the same way as Each does,
+// but lets the callback skip subtrees or stop the traversal.
+func (t *tree[V]) Walk(callback WalkFunc[V], options ...int) error {
+	if err := t.eachHelper(t.root, callback, traverseOptions(options)); err != SkipAll {
+		return err
+	}
+	return nil
+}
+
+// Iterates over all leaves whose keys start with the passed in prefix in key order,
+// or in descending key order if the TraverseReverse option is set.
+func (t *tree[V]) EachPrefix(prefix Key, callback Callback[V], options ...int) {
+	t.eachHelper(t.prefixHelper(t.root, prefix, 0, nil), walkCallback(callback), leavesOnly(traverseOptions(options)))
+}
+
+// Helper function that descends the tree the same way as searchHelper does.
+// Returns the node whose subtree contains exactly the keys starting with the passed in prefix,
+// or nil if there are no such keys. If visit isn't nil, it's called for every inner node
+// on the way down along with the key of the child that is followed.
+func (t *tree[V]) prefixHelper(current *artNode[V], prefix []byte, depth int, visit func(parent *artNode[V], key byte)) *artNode[V] {
+	for current != nil {
+		// A leaf is reached due to lazy expansion, so it has to be checked completely.
+		if current.isLeaf() {
+			if bytes.HasPrefix(current.leaf().key, prefix) {
+				return current
+			}
+			return nil
+		}
+
+		// The whole prefix was consumed, so every key below starts with it.
+		if depth >= len(prefix) {
+			return current
+		}
+
+		// Check if the prefix mismatches the current compressed path.
+		// It's fine if the prefix ends in the middle of the compressed path.
+		if mismatch := current.prefixMismatch(prefix, depth); mismatch != current.node().prefixLen {
+			if depth+mismatch >= len(prefix) {
+				return current
+			}
+			return nil
+		}
+		depth += current.node().prefixLen
+
+		if depth >= len(prefix) {
+			return current
+		}
+
+		if visit != nil {
+			visit(current, prefix[depth])
+		}
+		current = *(current.findChild(prefix[depth]))
+		depth++
+	}
+
+	return nil
 }
 
-func (t *tree) Size() int {
+// Iterates over all leaves with keys between start and end in key order.
+// By default start is inclusive and end is exclusive, a nil bound is open.
+// An empty non-nil bound is the empty key, so such an end bound excludes every key.
+// Keys are visited in descending order if the TraverseReverse option is set.
+func (t *tree[V]) Range(start, end Key, callback Callback[V], options ...int) {
+	t.rangeHelper(t.root, start, end, 0, start != nil, end != nil, leavesOnly(traverseOptions(options)), walkCallback(callback))
+}
+
+// Recursive helper for iterating over a range of keys.
+// The checkStart and checkEnd flags tell whether the path to the current node
+// is equal to the corresponding bound so far, otherwise the bound is already satisfied
+// for the whole subtree and doesn't need to be compared anymore.
+func (t *tree[V]) rangeHelper(current *artNode[V], start, end []byte, depth int, checkStart, checkEnd bool, opts int, callback WalkFunc[V]) error {
+	if current == nil {
+		return nil
+	}
+
+	depth, checkStart, checkEnd, ok := clipRange(current, start, end, depth, checkStart, checkEnd, opts)
+	if !ok {
+		return nil
+	}
+
+	if current.isLeaf() {
+		if err := callback(current); err != SkipNode {
+			return err
+		}
+		return nil
+	}
+
+	// The whole subtree is inside of the range.
+	if !checkStart && !checkEnd {
+		return t.eachHelper(current, callback, opts)
+	}
+
+	// The bounds that continue below the current node decide which children are visited.
+	// Once a bound is consumed completely, the keys of the children are greater than it.
+	startBelow := checkStart && depth < len(start)
+	endBelow := checkEnd && depth < len(end)
+	reverse := opts&TraverseReverse != 0
+
+	// The terminal child is smaller than every other child, and it's out of range
+	// if the start key continues below. Otherwise it's compared as a leaf.
+	terminal := *current.terminal()
+	if terminal != nil && !startBelow && !reverse {
+		if err := t.rangeHelper(terminal, start, end, depth, checkStart, checkEnd, opts, callback); err != nil {
+			return err
+		}
+	}
+
+	// Children are greater than an end key which is consumed completely.
+	if !checkEnd || endBelow {
+		// Children with keys out of [lo, hi] are pruned,
+		// and only the children on the bounds keep checking them.
+		lo, hi := byte(0), byte(255)
+		if startBelow {
+			lo = start[depth]
+		}
+		if endBelow {
+			hi = end[depth]
+		}
+
+		var err error
+		current.eachChildBetween(lo, hi, reverse, func(key byte, child *artNode[V]) bool {
+			err = t.rangeHelper(child, start, end, depth+1, startBelow && key == lo, endBelow && key == hi, opts, callback)
+			return err == nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if terminal != nil && !startBelow && reverse {
+		return t.rangeHelper(terminal, start, end, depth, checkStart, checkEnd, opts, callback)
+	}
+	return nil
+}
+
+// Returns a new iterator over the leaves of the tree.
+func (t *tree[V]) Iterator() Iterator[V] {
+	return newIterator[V](t)
+}
+
+func (t *tree[V]) Size() int {
 	return int(t.size)
 }
 
 // Recursive helper for iterative over the tree.  Iterates over all nodes in the tree,
 // executing the passed in callback as specified by the passed in traversal type.
-func (t *tree) eachHelper(current *artNode, callback Callback) {
+// Children of a node are skipped if the callback returns SkipNode,
+// and the traversal stops if it returns any other error.
+func (t *tree[V]) eachHelper(current *artNode[V], callback WalkFunc[V], opts int) error {
 	// Bail early if there's no node to iterate over
 	if current == nil {
-		return
+		return nil
+	}
+
+	visit := opts&kindOption(current.kind) != 0
+	postOrder := opts&TraversePostOrder != 0
+
+	if visit && !postOrder {
+		if err := callback(current); err != nil {
+			if err == SkipNode {
+				return nil
+			}
+			return err
+		}
+	}
+
+	if err := t.eachNodeChildren(current, callback, opts); err != nil {
+		return err
 	}
 
-	callback(current)
+	// Children are already visited, so there is nothing to skip.
+	if visit && postOrder {
+		if err := callback(current); err != SkipNode {
+			return err
+		}
+	}
+
+	return nil
+}
 
+// Iterates over the children of the passed in node in the order of traversal.
+func (t *tree[V]) eachNodeChildren(current *artNode[V], callback WalkFunc[V], opts int) error {
 	switch current.kind {
 	case Node4:
-		t.eachChildren(current.node4().children[:], callback)
+		return t.eachChildren(current.node4().children[:], callback, opts)
 
 	case Node16:
-		t.eachChildren(current.node16().children[:], callback)
+		return t.eachChildren(current.node16().children[:], callback, opts)
 
 	// Nodes of type Node48 do not necessarily store their children in sorted order.
 	// So we must instead iterate over their keys, acccess the children, and iterate properly.
 	case Node48:
 		node := current.node48()
+		reverse := opts&TraverseReverse != 0
+
 		child := node.children[node48Max]
-		if child != nil {
-			t.eachHelper(child, callback)
+		if child != nil && !reverse {
+			if err := t.eachHelper(child, callback, opts); err != nil {
+				return err
+			}
 		}
 
-		for _, i := range node.keys {
-			if i > 0 {
+		for k := range node.keys {
+			if reverse {
+				k = len(node.keys) - 1 - k
+			}
+			if i := node.keys[k]; i > 0 {
 				next := current.node48().children[i-1]
 				if next != nil {
-					t.eachHelper(next, callback)
+					if err := t.eachHelper(next, callback, opts); err != nil {
+						return err
+					}
 				}
 			}
 		}
 
+		if child != nil && reverse {
+			return t.eachHelper(child, callback, opts)
+		}
+
 	case Node256:
-		t.eachChildren(current.node256().children[:], callback)
+		return t.eachChildren(current.node256().children[:], callback, opts)
 	}
+
+	return nil
 }
 
-func (t *tree) eachChildren(children []*artNode, callback Callback) {
-	nullChild := children[len(children)-1]
-	if nullChild != nil {
-		t.eachHelper(nullChild, callback)
+// Iterates over the passed in children in ascending order,
+// or in descending order if the TraverseReverse option is set.
+func (t *tree[V]) eachChildren(children []*artNode[V], callback WalkFunc[V], opts int) error {
+	reverse := opts&TraverseReverse != 0
+
+	// The last slot holds the terminal child, which goes before the others.
+	terminal := children[len(children)-1]
+	children = children[:len(children)-1]
+
+	if terminal != nil && !reverse {
+		if err := t.eachHelper(terminal, callback, opts); err != nil {
+			return err
+		}
 	}
 
-	for _, child := range children {
-		if child != nil && child != nullChild {
-			t.eachHelper(child, callback)
+	for i := range children {
+		if reverse {
+			i = len(children) - 1 - i
 		}
+		if child := children[i]; child != nil {
+			if err := t.eachHelper(child, callback, opts); err != nil {
+				return err
+			}
+		}
+	}
+
+	if terminal != nil && reverse {
+		return t.eachHelper(terminal, callback, opts)
 	}
+
+	return nil
 }
 
 func memcpy(dest []byte, src []byte, numBytes int) {
@@ -316,3 +1031,110 @@ func memmove(dest []byte, src []byte, numBytes int) {
 		dest[i] = src[i]
 	}
 }
+
+// Returns the key and the value of the passed in leaf, or false if there is no leaf.
+func leafEntry[V any](node *artNode[V]) (Key, V, bool) {
+	if node == nil {
+		var zero V
+		return nil, zero, false
+	}
+	return node.leaf().key, node.leaf().value, true
+}
+
+// Wraps the passed in callback in order to use it as a WalkFunc[V].
+func walkCallback[V any](callback Callback[V]) WalkFunc[V] {
+	return func(node Node[V]) error {
+		callback(node)
+		return nil
+	}
+}
+
+// Combines the passed in traversal options into a single bit set.
+// All kinds of nodes are visited unless some of them are chosen explicitly.
+func traverseOptions(options []int) int {
+	opts := 0
+	for _, opt := range options {
+		opts |= opt
+	}
+	if opts&TraverseAll == 0 {
+		opts |= TraverseAll
+	}
+	return opts
+}
+
+// Restricts the passed in traversal options to visit leaves only.
+func leavesOnly(opts int) int {
+	return opts&^TraverseNode | TraverseLeaf
+}
+
+// Returns the traversal option that chooses nodes of the passed in kind.
+// Options of kinds are declared in the same order as kinds themselves.
+func kindOption(kind Kind) int {
+	return TraverseLeaf << kind
+}
+
+// Compares the passed in node at the specified depth with the bounds of a range, which is shared
+// by all of the range operations. Leaves are compared completely, since they might be reached
+// due to lazy expansion. The compressed path of an inner node is compared with the bounds
+// that still have to be checked, to prune the subtree or to stop checking a bound.
+// Returns the depth below the compressed path, the updated flags,
+// and false if the node is out of the range.
+func clipRange[V any](current *artNode[V], start, end []byte, depth int, checkStart, checkEnd bool, opts int) (int, bool, bool, bool) {
+	if current.isLeaf() {
+		key := current.leaf().key
+		if checkStart {
+			if c := bytes.Compare(key, start); c < 0 || c == 0 && opts&RangeExcludeStart != 0 {
+				return depth, false, false, false
+			}
+		}
+		if checkEnd {
+			if c := bytes.Compare(key, end); c > 0 || c == 0 && opts&RangeIncludeEnd == 0 {
+				return depth, false, false, false
+			}
+		}
+		return depth, false, false, true
+	}
+
+	if current.node().prefixLen != 0 && (checkStart || checkEnd) {
+		prefix := current.fullPrefix(depth)
+
+		if checkStart {
+			c := comparePath(prefix, start, depth)
+			if c < 0 {
+				return depth, false, false, false
+			}
+			checkStart = c == 0
+		}
+
+		if checkEnd {
+			c := comparePath(prefix, end, depth)
+			if c > 0 {
+				return depth, false, false, false
+			}
+			checkEnd = c == 0
+		}
+	}
+
+	return depth + current.node().prefixLen, checkStart, checkEnd, true
+}
+
+// Compares the compressed path with the bound starting at the specified depth.
+// Returns 0 if the path matches the bound so far, otherwise returns the sign
+// of the difference between the keys below the path and the bound.
+func comparePath(path []byte, bound []byte, depth int) int {
+	var rest []byte
+	if depth < len(bound) {
+		rest = bound[depth:]
+	}
+
+	limit := min(len(path), len(rest))
+	if c := bytes.Compare(path[:limit], rest[:limit]); c != 0 {
+		return c
+	}
+
+	// The bound ends within the path, so all of the keys below are greater.
+	if len(rest) < len(path) {
+		return 1
+	}
+	return 0
+}
@@ -5,10 +5,13 @@
 package art
 
 import (
+	"bytes"
 	"encoding/binary"
+	"errors"
 	_ "fmt"
 	_ "log"
 	"math/rand"
+	"sort"
 	"testing"
 
 	"github.com/k33nice/libart/internal/test"
@@ -18,7 +21,7 @@ import (
 // @spec: After a single insert operation, the tree should have a size of 1
 //        and the root should be a leaf.
 func TestArtTreeInsert(t *testing.T) {
-	tree := newArt()
+	tree := newArt[Value]()
 	tree.Insert(Key("hello"), "world")
 
 	assert.Equal(t, int64(1), tree.size)
@@ -28,7 +31,7 @@ func TestArtTreeInsert(t *testing.T) {
 // @spec: After a single insert operation, the tree should be able
 //        to retrieve there term it had inserted earlier
 func TestArtTreeInsertAndSearch(t *testing.T) {
-	tree := newArt()
+	tree := newArt[Value]()
 
 	tree.Insert(Key("hello"), "world")
 	res := tree.Search(Key("hello"))
@@ -40,7 +43,7 @@ func TestArtTreeInsertAndSearch(t *testing.T) {
 //        The tree should be able to successfully retrieve any of
 //        the previous inserted values
 func TestArtTreeInsert2AndSearch(t *testing.T) {
-	tree := newArt()
+	tree := newArt[Value]()
 
 	tree.Insert(Key("hello"), "world")
 	tree.Insert(Key("yo"), "earth")
@@ -57,7 +60,7 @@ func TestArtTreeInsert2AndSearch(t *testing.T) {
 // An Art Node with a similar prefix should be split into new nodes accordingly
 // And should be searchable as intended.
 func TestArtTreeInsert2WithSimilarPrefix(t *testing.T) {
-	tree := newArt()
+	tree := newArt[Value]()
 
 	tree.Insert(Key("a"), "a")
 	tree.Insert(Key("aa"), "aa")
@@ -71,7 +74,7 @@ func TestArtTreeInsert2WithSimilarPrefix(t *testing.T) {
 // An Art Node with a similar prefix should be split into new nodes accordingly
 // And should be searchable as intended.
 func TestArtTreeInsert3AndSearchWords(t *testing.T) {
-	tree := newArt()
+	tree := newArt[Value]()
 
 	searchTerms := []string{"A", "a", "aa"}
 
@@ -86,6 +89,114 @@ func TestArtTreeInsert3AndSearchWords(t *testing.T) {
 	}
 }
 
+// Inserting an existing key should replace its value, return the previous one
+// and keep the size of the tree untouched.
+func TestInsertReplacesExistingValue(t *testing.T) {
+	tree := newArt[Value]()
+
+	old, updated := tree.Insert(Key("hello"), "world")
+	assert.Nil(t, old)
+	assert.False(t, updated)
+
+	tree.Insert(Key("yo"), "earth")
+
+	old, updated = tree.Insert(Key("hello"), "there")
+	assert.Equal(t, "world", old)
+	assert.True(t, updated)
+
+	assert.Equal(t, "there", tree.Search(Key("hello")))
+	assert.Equal(t, "earth", tree.Search(Key("yo")))
+	assert.Equal(t, 2, tree.Size())
+}
+
+// InsertIfAbsent should only store the value of a new key
+// and report the stored value of an existing one.
+func TestInsertIfAbsent(t *testing.T) {
+	tree := newArt[Value]()
+
+	old, inserted := tree.InsertIfAbsent(Key("hello"), "world")
+	assert.Nil(t, old)
+	assert.True(t, inserted)
+
+	old, inserted = tree.InsertIfAbsent(Key("hello"), "there")
+	assert.Equal(t, "world", old)
+	assert.False(t, inserted)
+
+	assert.Equal(t, "world", tree.Search(Key("hello")))
+	assert.Equal(t, 1, tree.Size())
+}
+
+// Reinserting every word of the dictionary should replace the values
+// without changing the size of the tree.
+func TestInsertManyWordsTwiceAndEnsureReplaced(t *testing.T) {
+	tree := newArt[Value]()
+
+	words := test.LoadTestFile("test/data/words.txt")
+
+	for _, w := range words {
+		tree.Insert(w, w)
+	}
+
+	for i, w := range words {
+		old, updated := tree.Insert(w, i)
+		assert.True(t, updated)
+		assert.Equal(t, w, old)
+	}
+
+	assert.Equal(t, len(words), tree.Size())
+	for i, w := range words {
+		assert.Equal(t, i, tree.Search(w))
+	}
+}
+
+// A typed tree should store values without boxing them
+// and return the zero value of its type for missing keys.
+func TestTypedTree(t *testing.T) {
+	tree := NewTree[int]()
+
+	for i, w := range []string{"a", "b", "c"} {
+		tree.Insert(Key(w), i+1)
+	}
+
+	assert.Equal(t, 2, tree.Search(Key("b")))
+	assert.Equal(t, 0, tree.Search(Key("d")))
+
+	old, updated := tree.Insert(Key("b"), 20)
+	assert.True(t, updated)
+	assert.Equal(t, 2, old)
+
+	sum := 0
+	for _, value := range tree.All() {
+		sum += value
+	}
+	assert.Equal(t, 24, sum)
+
+	tree.Each(func(node Node[int]) {
+		sum -= node.Value()
+	}, TraverseLeaf)
+	assert.Zero(t, sum)
+
+	value, deleted := tree.Delete(Key("c"))
+	assert.True(t, deleted)
+	assert.Equal(t, 3, value)
+
+	var _ Tree[Value] = New()
+}
+
+// Inserting a small value into a typed tree should only allocate the leaf and its key.
+func TestTypedTreeInsertAllocations(t *testing.T) {
+	tree := newArt[int]()
+	tree.Insert(Key("a"), 1)
+	tree.Insert(Key("b"), 2)
+
+	key := Key("c")
+	allocs := testing.AllocsPerRun(100, func() {
+		tree.Insert(key, 1000)
+		tree.Delete(key)
+	})
+	assert.Equal(t, float64(3), allocs)
+}
+
 func TestTreeInsertAndGrowToBiggerNode(t *testing.T) {
 	var testData = []struct {
 		totalNodes byte
@@ -97,7 +208,7 @@ func TestTreeInsertAndGrowToBiggerNode(t *testing.T) {
 	}
 
 	for _, data := range testData {
-		tree := newArt()
+		tree := newArt[Value]()
 		for i := byte(0); i < data.totalNodes; i++ {
 			tree.Insert(Key{i}, i)
 		}
@@ -109,7 +220,7 @@ func TestTreeInsertAndGrowToBiggerNode(t *testing.T) {
 // After inserting many words into the tree, we should be able to successfully retreive all of them
 // To ensure their presence in the tree.
 func TestInsertManyWordsAndEnsureSearchResultAndMinimumMaximum(t *testing.T) {
-	tree := newArt()
+	tree := newArt[Value]()
 
 	words := test.LoadTestFile("test/data/words.txt")
 
@@ -136,7 +247,7 @@ func TestInsertManyWordsAndEnsureSearchResultAndMinimumMaximum(t *testing.T) {
 // After inserting many random UUIDs into the tree, we should be able to successfully retreive all of them
 // To ensure their presence in the tree.
 func TestInsertManyUUIDsAndEnsureSearchAndMinimumMaximum(t *testing.T) {
-	tree := newArt()
+	tree := newArt[Value]()
 
 	uuids := test.LoadTestFile("test/data/uuid.txt")
 
@@ -160,9 +271,615 @@ func TestInsertManyUUIDsAndEnsureSearchAndMinimumMaximum(t *testing.T) {
 	assert.Equal(t, []byte("ffffb7f1-20de-4a46-a3ec-8c87d5c7fce0"), maximum.Value().([]byte))
 }
 
+// Minimum and Maximum of an empty tree should report that nothing was found.
+func TestMinimumMaximumOfEmptyTree(t *testing.T) {
+	tree := newArt[Value]()
+
+	key, value, ok := tree.Minimum()
+	assert.False(t, ok)
+	assert.Nil(t, key)
+	assert.Nil(t, value)
+
+	_, _, ok = tree.Maximum()
+	assert.False(t, ok)
+
+	_, _, ok = tree.PopMin()
+	assert.False(t, ok)
+
+	_, _, ok = tree.PopMax()
+	assert.False(t, ok)
+}
+
+// Minimum and Maximum should return the smallest and the largest keys
+// for all types of root node.
+func TestMinimumMaximumForAllNodeTypes(t *testing.T) {
+	for _, total := range []int{1, 4, 16, 48, 256} {
+		tree := newArt[Value]()
+		for i := total - 1; i >= 0; i-- {
+			tree.Insert(Key{byte(i)}, i)
+		}
+
+		key, value, ok := tree.Minimum()
+		assert.True(t, ok)
+		assert.Equal(t, Key{0}, key)
+		assert.Equal(t, 0, value)
+
+		key, value, ok = tree.Maximum()
+		assert.True(t, ok)
+		assert.Equal(t, Key{byte(total - 1)}, key)
+		assert.Equal(t, total-1, value)
+	}
+}
+
+// Popping the minimum key repeatedly should drain the tree in ascending order
+// and popping the maximum key should drain it in descending order.
+func TestPopMinPopMaxDrainTree(t *testing.T) {
+	words := test.LoadTestFile("test/data/words.txt")[:1000]
+
+	tree := newArt[Value]()
+	for _, w := range words {
+		tree.Insert(w, w)
+	}
+
+	var prev Key
+	for tree.Size() > 0 {
+		key, value, ok := tree.PopMin()
+		assert.True(t, ok)
+		assert.Equal(t, key, value)
+		assert.True(t, bytes.Compare(prev, key) < 0)
+		prev = key
+	}
+	assert.Nil(t, tree.root)
+
+	for _, w := range words {
+		tree.Insert(w, w)
+	}
+
+	prev = nil
+	for tree.Size() > 0 {
+		key, _, ok := tree.PopMax()
+		assert.True(t, ok)
+		if prev != nil {
+			assert.True(t, bytes.Compare(key, prev) < 0)
+		}
+		prev = key
+	}
+	assert.Nil(t, tree.root)
+}
+
+// LongestPrefix should return the longest stored key that is a prefix of the query.
+func TestLongestPrefix(t *testing.T) {
+	tree := newArt[Value]()
+
+	_, _, ok := tree.LongestPrefix(Key("anything"))
+	assert.False(t, ok)
+
+	routes := []string{"/", "/api", "/api/v1", "/api/v1/users/settings/notifications", "/static"}
+	for _, route := range routes {
+		tree.Insert(Key(route), route)
+	}
+
+	cases := map[string]string{
+		"/":                                    "/",
+		"/ap":                                  "/",
+		"/api":                                 "/api",
+		"/api/":                                "/api",
+		"/api/v1/users":                        "/api/v1",
+		"/api/v1/users/settings/notifications": "/api/v1/users/settings/notifications",
+		"/api/v1/users/settings/notifications/mail": "/api/v1/users/settings/notifications",
+		"/api/v1/users/settings/notificationz":      "/api/v1",
+		"/static/css/main.css":                      "/static",
+		"/statik":                                   "/",
+	}
+	for query, expected := range cases {
+		key, value, ok := tree.LongestPrefix(Key(query))
+		assert.True(t, ok, query)
+		assert.Equal(t, Key(expected), key, query)
+		assert.Equal(t, expected, value, query)
+	}
+
+	_, _, ok = tree.LongestPrefix(Key("api"))
+	assert.False(t, ok)
+	_, _, ok = tree.LongestPrefix(nil)
+	assert.False(t, ok)
+
+	tree.Insert(nil, "root")
+	key, value, ok := tree.LongestPrefix(Key("api"))
+	assert.True(t, ok)
+	assert.Equal(t, Key{}, key)
+	assert.Equal(t, "root", value)
+}
+
+// EachPrefixOf should visit every stored prefix of the key from the shortest to the longest.
+func TestEachPrefixOf(t *testing.T) {
+	tree := newArt[Value]()
+
+	paths := []string{"/a/b/c", "/a", "", "/a/b", "/a/bc", "/a/b/c/d", "/b"}
+	for _, path := range paths {
+		tree.Insert(Key(path), path)
+	}
+
+	collect := func(key string) []string {
+		var actual []string
+		tree.EachPrefixOf(Key(key), func(node Node[Value]) {
+			actual = append(actual, node.Value().(string))
+		})
+		return actual
+	}
+
+	assert.Equal(t, []string{"", "/a", "/a/b", "/a/b/c"}, collect("/a/b/c"))
+	assert.Equal(t, []string{"", "/a", "/a/b", "/a/b/c", "/a/b/c/d"}, collect("/a/b/c/d/e"))
+	assert.Equal(t, []string{"", "/a", "/a/b"}, collect("/a/b/x"))
+	assert.Equal(t, []string{"", "/a", "/a/b", "/a/bc"}, collect("/a/bcd"))
+	assert.Equal(t, []string{""}, collect("/c"))
+	assert.Equal(t, []string{""}, collect(""))
+
+	tree.Delete(nil)
+	assert.Empty(t, collect("/c"))
+	assert.Equal(t, []string{"/b"}, collect("/b/a"))
+}
+
+// LongestPrefix should agree with searching shorter and shorter slices of the query.
+func TestLongestPrefixManyWords(t *testing.T) {
+	tree := newArt[Value]()
+	words := test.LoadTestFile("test/data/words.txt")
+	for _, w := range words {
+		tree.Insert(w, w)
+	}
+
+	for _, w := range words[:2000] {
+		query := append(append(Key(nil), w...), "xyz"...)
+		expected := Key(nil)
+		for i := len(query); i >= 0; i-- {
+			if _, found := tree.Lookup(query[:i]); found {
+				expected = query[:i]
+				break
+			}
+		}
+
+		key, value, ok := tree.LongestPrefix(query)
+		assert.True(t, ok)
+		assert.Equal(t, expected, key)
+		assert.Equal(t, expected, value)
+	}
+}
+
+// Floor, Ceiling, Predecessor and Successor should find the nearest keys of absent keys as well.
+func TestFloorCeilingPredecessorSuccessor(t *testing.T) {
+	tree := newArt[Value]()
+
+	_, _, ok := tree.Floor(Key("a"))
+	assert.False(t, ok)
+	_, _, ok = tree.Successor(nil)
+	assert.False(t, ok)
+
+	for _, ts := range []string{"2019-01-01", "2019-01-05", "2019-02-01", "2020"} {
+		tree.Insert(Key(ts), ts)
+	}
+
+	key, value, ok := tree.Floor(Key("2019-01-31"))
+	assert.True(t, ok)
+	assert.Equal(t, Key("2019-01-05"), key)
+	assert.Equal(t, "2019-01-05", value)
+
+	key, _, _ = tree.Floor(Key("2019-01-05"))
+	assert.Equal(t, Key("2019-01-05"), key)
+	key, _, _ = tree.Predecessor(Key("2019-01-05"))
+	assert.Equal(t, Key("2019-01-01"), key)
+	key, _, _ = tree.Ceiling(Key("2019-01-05"))
+	assert.Equal(t, Key("2019-01-05"), key)
+	key, _, _ = tree.Successor(Key("2019-01-05"))
+	assert.Equal(t, Key("2019-02-01"), key)
+	key, _, _ = tree.Ceiling(Key("2019-02"))
+	assert.Equal(t, Key("2019-02-01"), key)
+	key, _, _ = tree.Floor(Key("2019-02"))
+	assert.Equal(t, Key("2019-01-05"), key)
+	key, _, _ = tree.Successor(Key("2019-02-01"))
+	assert.Equal(t, Key("2020"), key)
+
+	_, _, ok = tree.Floor(Key("2018"))
+	assert.False(t, ok)
+	_, _, ok = tree.Predecessor(Key("2019-01-01"))
+	assert.False(t, ok)
+	_, _, ok = tree.Ceiling(Key("2020-01"))
+	assert.False(t, ok)
+	_, _, ok = tree.Successor(Key("2020"))
+	assert.False(t, ok)
+}
+
+// Nearest key queries should agree with a binary search over the sorted keys.
+func TestNearestKeysForAllNodeTypes(t *testing.T) {
+	rng := rand.New(rand.NewSource(7))
+
+	for _, n := range []int{1, 3, 10, 40, 100, 1000} {
+		tree := newArt[Value]()
+		var keys []string
+		for len(keys) < n {
+			key := make([]byte, rng.Intn(4))
+			for i := range key {
+				key[i] = byte(rng.Intn(256))
+			}
+			// Some of the keys share a prefix longer than the stored part of a compressed path.
+			if rng.Intn(2) == 0 {
+				key = append(Key("a-long-shared-prefix/"), key...)
+			}
+			if _, updated := tree.Insert(key, string(key)); !updated {
+				keys = append(keys, string(key))
+			}
+		}
+		sort.Strings(keys)
+
+		for i := 0; i < 500; i++ {
+			query := make([]byte, rng.Intn(5))
+			for j := range query {
+				query[j] = byte(rng.Intn(256))
+			}
+			switch i % 5 {
+			case 0:
+				query = Key(keys[rng.Intn(n)])
+			case 1:
+				query = append(Key("a-long-shared-prefix/")[:rng.Intn(22)], query...)
+			}
+
+			// Index of the first key greater than or equal to the query, and greater than it.
+			ge := sort.SearchStrings(keys, string(query))
+			gt := sort.Search(len(keys), func(i int) bool { return keys[i] > string(query) })
+
+			assertEntry := func(index int, key Key, value Value, ok bool) {
+				if index < 0 || index >= len(keys) {
+					assert.False(t, ok, "%q", query)
+					return
+				}
+				assert.True(t, ok, "%q", query)
+				assert.Equal(t, keys[index], string(key), "%q", query)
+				assert.Equal(t, keys[index], value, "%q", query)
+			}
+
+			key, value, ok := tree.Floor(query)
+			assertEntry(gt-1, key, value, ok)
+			key, value, ok = tree.Predecessor(query)
+			assertEntry(ge-1, key, value, ok)
+			key, value, ok = tree.Ceiling(query)
+			assertEntry(ge, key, value, ok)
+			key, value, ok = tree.Successor(query)
+			assertEntry(gt, key, value, ok)
+		}
+	}
+}
+
+// Every inner node must count the leaves of its subtree.
+func assertLeafCounts(t *testing.T, tree *tree[Value]) {
+	tree.Each(func(node Node[Value]) {
+		n := node.(*artNode[Value])
+		count := 0
+		for pos := 0; pos <= n.lastPosition(); pos++ {
+			count += n.childAt(pos).leafCount()
+		}
+		assert.Equal(t, count, n.leafCount())
+	}, TraverseNode)
+	assert.Equal(t, tree.Size(), tree.root.leafCount())
+}
+
+// Rank, Select and Count should answer order statistics queries for pagination.
+func TestRankSelectCount(t *testing.T) {
+	tree := newArt[Value]()
+
+	_, _, ok := tree.Select(0)
+	assert.False(t, ok)
+	assert.Zero(t, tree.Rank(Key("a")))
+	assert.Zero(t, tree.Count(nil, nil))
+	assert.Zero(t, tree.CountPrefix(nil))
+
+	keys := []string{"", "a", "ab", "abc", "abd", "b", "ba", "c"}
+	for i := len(keys) - 1; i >= 0; i-- {
+		tree.Insert(Key(keys[i]), i)
+	}
+	assertLeafCounts(t, tree)
+
+	for i, key := range keys {
+		assert.Equal(t, i, tree.Rank(Key(key)))
+
+		selected, value, ok := tree.Select(i)
+		assert.True(t, ok)
+		assert.Equal(t, Key(key), selected)
+		assert.Equal(t, i, value)
+	}
+	_, _, ok = tree.Select(len(keys))
+	assert.False(t, ok)
+	_, _, ok = tree.Select(-1)
+	assert.False(t, ok)
+
+	assert.Equal(t, 4, tree.Rank(Key("abcd")))
+	assert.Equal(t, 8, tree.Rank(Key("d")))
+
+	assert.Equal(t, 8, tree.Count(nil, nil))
+	assert.Equal(t, 4, tree.Count(Key("a"), Key("b")))
+	assert.Equal(t, 5, tree.Count(Key("a"), Key("b"), RangeIncludeEnd))
+	assert.Equal(t, 4, tree.Count(Key("a"), Key("b"), RangeIncludeEnd, RangeExcludeStart))
+	assert.Equal(t, 2, tree.Count(nil, Key("ab")))
+	assert.Equal(t, 3, tree.Count(Key("b"), nil))
+	assert.Zero(t, tree.Count(Key("c"), Key("a")))
+
+	assert.Equal(t, 8, tree.CountPrefix(nil))
+	assert.Equal(t, 4, tree.CountPrefix(Key("a")))
+	assert.Equal(t, 3, tree.CountPrefix(Key("ab")))
+	assert.Equal(t, 1, tree.CountPrefix(Key("abc")))
+	assert.Zero(t, tree.CountPrefix(Key("abcd")))
+	assert.Zero(t, tree.CountPrefix(Key("d")))
+}
+
+// Leaf counts must stay correct while nodes grow, shrink and collapse.
+func TestOrderStatisticsWithInsertAndDelete(t *testing.T) {
+	rng := rand.New(rand.NewSource(17))
+	tree := newArt[Value]()
+	present := make(map[string]bool)
+
+	randomKey := func() Key {
+		key := make(Key, rng.Intn(3))
+		for i := range key {
+			key[i] = byte(rng.Intn(64))
+		}
+		if rng.Intn(3) == 0 {
+			key = append(Key("a-long-shared-prefix/"), key...)
+		}
+		return key
+	}
+
+	for round := 0; round < 20; round++ {
+		for i := 0; i < 300; i++ {
+			key := randomKey()
+			if round%4 == 3 || rng.Intn(3) == 0 {
+				_, deleted := tree.Delete(key)
+				assert.Equal(t, present[string(key)], deleted)
+				delete(present, string(key))
+			} else {
+				tree.Insert(key, string(key))
+				present[string(key)] = true
+			}
+		}
+		assertLeafCounts(t, tree)
+
+		var sorted []string
+		for key := range present {
+			sorted = append(sorted, key)
+		}
+		sort.Strings(sorted)
+
+		for i, key := range sorted {
+			selected, _, ok := tree.Select(i)
+			assert.True(t, ok)
+			assert.Equal(t, key, string(selected))
+		}
+
+		for i := 0; i < 100; i++ {
+			start, end := randomKey(), randomKey()
+			lo := sort.SearchStrings(sorted, string(start))
+			hi := sort.SearchStrings(sorted, string(end))
+
+			assert.Equal(t, lo, tree.Rank(start))
+			assert.Equal(t, max(hi-lo, 0), tree.Count(start, end))
+
+			prefix := start[:rng.Intn(len(start)+1)]
+			count := 0
+			for _, key := range sorted {
+				if bytes.HasPrefix(Key(key), prefix) {
+					count++
+				}
+			}
+			assert.Equal(t, count, tree.CountPrefix(prefix))
+		}
+	}
+}
+
+// RandomKey should return every key with about the same frequency
+// and be deterministic for a seeded source.
+func TestRandomKey(t *testing.T) {
+	tree := newArt[Value]()
+
+	_, _, ok := tree.RandomKey(rand.New(rand.NewSource(1)))
+	assert.False(t, ok)
+
+	keys := []string{"", "a", "ab", "abc", "abcdefghijklmnop", "b", "user:1:session", "user:2:session", "user:3"}
+	for i := 0; i < 100; i++ {
+		keys = append(keys, string([]byte{'x', byte(i)}))
+	}
+	for _, key := range keys {
+		tree.Insert(Key(key), key)
+	}
+
+	const samples = 100000
+	hits := make(map[string]int)
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < samples; i++ {
+		key, value, ok := tree.RandomKey(rng)
+		assert.True(t, ok)
+		assert.Equal(t, string(key), value)
+		hits[string(key)]++
+	}
+
+	expected := samples / len(keys)
+	assert.Len(t, hits, len(keys))
+	for key, n := range hits {
+		assert.InDelta(t, expected, n, float64(expected)/3, "%q", key)
+	}
+
+	// The same seed must produce the same sequence of keys.
+	first, second := rand.New(rand.NewSource(5)), rand.New(rand.NewSource(5))
+	for i := 0; i < 100; i++ {
+		a, _, _ := tree.RandomKey(first)
+		b, _, _ := tree.RandomKey(second)
+		assert.Equal(t, a, b)
+	}
+}
+
+// RandomKeyWithPrefix should only return keys that start with the prefix.
+func TestRandomKeyWithPrefix(t *testing.T) {
+	tree := newArt[Value]()
+	rng := rand.New(rand.NewSource(3))
+
+	for _, key := range []string{"user:1:session", "user:1:profile", "user:2:session", "users", "admin"} {
+		tree.Insert(Key(key), key)
+	}
+
+	hits := make(map[string]int)
+	for i := 0; i < 3000; i++ {
+		key, _, ok := tree.RandomKeyWithPrefix(Key("user:"), rng)
+		assert.True(t, ok)
+		hits[string(key)]++
+	}
+	assert.Len(t, hits, 3)
+	for _, n := range hits {
+		assert.InDelta(t, 1000, n, 200)
+	}
+
+	key, _, ok := tree.RandomKeyWithPrefix(Key("user:1:s"), rng)
+	assert.True(t, ok)
+	assert.Equal(t, Key("user:1:session"), key)
+
+	_, _, ok = tree.RandomKeyWithPrefix(Key("user:3"), rng)
+	assert.False(t, ok)
+}
+
+// Update should insert, replace, keep or remove a key depending on the returned action.
+func TestUpdate(t *testing.T) {
+	tree := newArt[int]()
+	increment := func(old int, exists bool) (int, Action) {
+		return old + 1, UpdateStore
+	}
+
+	for i := 0; i < 3; i++ {
+		value, present := tree.Update(Key("hits"), increment)
+		assert.True(t, present)
+		assert.Equal(t, i+1, value)
+	}
+	assert.Equal(t, 1, tree.Size())
+
+	value, present := tree.Update(Key("missing"), func(old int, exists bool) (int, Action) {
+		assert.False(t, exists)
+		return 42, UpdateKeep
+	})
+	assert.False(t, present)
+	assert.Zero(t, value)
+	assert.Equal(t, 1, tree.Size())
+
+	value, present = tree.Update(Key("hits"), func(old int, exists bool) (int, Action) {
+		assert.True(t, exists)
+		assert.Equal(t, 3, old)
+		return 0, UpdateKeep
+	})
+	assert.True(t, present)
+	assert.Equal(t, 3, value)
+
+	_, present = tree.Update(Key("missing"), func(old int, exists bool) (int, Action) {
+		return 0, UpdateDelete
+	})
+	assert.False(t, present)
+
+	_, present = tree.Update(Key("hits"), func(old int, exists bool) (int, Action) {
+		return 0, UpdateDelete
+	})
+	assert.False(t, present)
+	assert.Zero(t, tree.Size())
+	assert.Nil(t, tree.root)
+}
+
+// Update should keep the tree consistent for keys in all kinds of nodes, including terminal children.
+func TestUpdateForAllNodeTypes(t *testing.T) {
+	rng := rand.New(rand.NewSource(23))
+	tree := newArt[Value]()
+	expected := make(map[string]int)
+
+	for i := 0; i < 20000; i++ {
+		key := make(Key, rng.Intn(3))
+		for j := range key {
+			key[j] = byte(rng.Intn(64))
+		}
+		if rng.Intn(2) == 0 {
+			key = append(Key("a-long-shared-prefix/")[:rng.Intn(22)], key...)
+		}
+
+		action := Action(rng.Intn(3))
+		value, present := tree.Update(key, func(old Value, exists bool) (Value, Action) {
+			n, ok := expected[string(key)]
+			assert.Equal(t, ok, exists)
+			if exists {
+				assert.Equal(t, n, old)
+			}
+			return i, action
+		})
+
+		switch action {
+		case UpdateStore:
+			expected[string(key)] = i
+		case UpdateDelete:
+			delete(expected, string(key))
+		}
+
+		n, ok := expected[string(key)]
+		assert.Equal(t, ok, present)
+		if ok {
+			assert.Equal(t, n, value)
+		}
+	}
+
+	assert.Equal(t, len(expected), tree.Size())
+	assertLeafCounts(t, tree)
+	for key, n := range expected {
+		value, found := tree.Lookup(Key(key))
+		assert.True(t, found)
+		assert.Equal(t, n, value)
+	}
+}
+
+// GetOrInsert should only insert absent keys.
+func TestGetOrInsert(t *testing.T) {
+	tree := newArt[string]()
+
+	actual, loaded := tree.GetOrInsert(Key("a"), "first")
+	assert.False(t, loaded)
+	assert.Equal(t, "first", actual)
+
+	actual, loaded = tree.GetOrInsert(Key("a"), "second")
+	assert.True(t, loaded)
+	assert.Equal(t, "first", actual)
+	assert.Equal(t, 1, tree.Size())
+}
+
+// CompareAndSwap and CompareAndDelete should only change the key if the stored value is equal.
+func TestCompareAndSwapAndDelete(t *testing.T) {
+	tree := newArt[Value]()
+	tree.Insert(Key("a"), 1)
+	tree.Insert(Key("ab"), "x")
+
+	assert.False(t, tree.CompareAndSwap(Key("a"), 2, 3, nil))
+	assert.True(t, tree.CompareAndSwap(Key("a"), 1, 3, nil))
+	assert.Equal(t, 3, tree.Search(Key("a")))
+	assert.False(t, tree.CompareAndSwap(Key("missing"), nil, 1, nil))
+	assert.Equal(t, 2, tree.Size())
+
+	assert.False(t, tree.CompareAndDelete(Key("a"), 1, nil))
+	assert.True(t, tree.CompareAndDelete(Key("a"), 3, nil))
+	assert.False(t, tree.CompareAndDelete(Key("a"), 3, nil))
+	assert.Equal(t, 1, tree.Size())
+
+	// A custom equality function allows to compare values that aren't comparable with ==.
+	tree.Insert(Key("list"), []int{1, 2})
+	equal := func(a, b Value) bool {
+		return len(a.([]int)) == len(b.([]int))
+	}
+	assert.True(t, tree.CompareAndSwap(Key("list"), []int{0, 0}, []int{1, 2, 3}, equal))
+	assert.True(t, tree.CompareAndDelete(Key("list"), []int{0, 0, 0}, equal))
+	assert.Equal(t, 1, tree.Size())
+
+	tree.Insert(Key("list"), []int{1, 2})
+	assert.Panics(t, func() {
+		tree.CompareAndSwap(Key("list"), []int{1, 2}, nil, nil)
+	})
+}
+
 // Inserting a single value into the tree and removing it should result in a nil tree root.
 func TestInsertAndRemove1(t *testing.T) {
-	tree := newArt()
+	tree := newArt[Value]()
 
 	tree.Insert(Key("test"), []byte("data"))
 
@@ -172,10 +889,475 @@ func TestInsertAndRemove1(t *testing.T) {
 	assert.Nil(t, tree.root)
 }
 
+// Lookup should distinguish a missing key from a key that stores a nil value.
+func TestLookupDistinguishesNilValue(t *testing.T) {
+	tree := newArt[Value]()
+
+	var typedNil *int
+	tree.Insert(Key("nil"), nil)
+	tree.Insert(Key("typed"), typedNil)
+
+	value, found := tree.Lookup(Key("nil"))
+	assert.True(t, found)
+	assert.Nil(t, value)
+
+	value, found = tree.Lookup(Key("typed"))
+	assert.True(t, found)
+	assert.Equal(t, typedNil, value)
+
+	value, found = tree.Lookup(Key("missing"))
+	assert.False(t, found)
+	assert.Nil(t, value)
+
+	_, found = tree.Lookup(Key("ni"))
+	assert.False(t, found)
+}
+
+// Delete should return the removed value and report whether the key was present.
+func TestDeleteReturnsRemovedValue(t *testing.T) {
+	tree := newArt[Value]()
+
+	tree.Insert(Key("test"), "data")
+	tree.Insert(Key("test2"), nil)
+
+	value, deleted := tree.Delete(Key("missing"))
+	assert.False(t, deleted)
+	assert.Nil(t, value)
+
+	value, deleted = tree.Delete(Key("test"))
+	assert.True(t, deleted)
+	assert.Equal(t, "data", value)
+
+	value, deleted = tree.Delete(Key("test"))
+	assert.False(t, deleted)
+	assert.Nil(t, value)
+
+	// The root is a leaf now, a mismatching key must not be removed.
+	_, deleted = tree.Delete(Key("test3"))
+	assert.False(t, deleted)
+
+	value, deleted = tree.Delete(Key("test2"))
+	assert.True(t, deleted)
+	assert.Nil(t, value)
+
+	assert.Zero(t, tree.size)
+	assert.Nil(t, tree.root)
+}
+
+// The empty key must be stored like any other key, and a nil key must refer to it.
+func TestEmptyKey(t *testing.T) {
+	tree := newArt[Value]()
+
+	_, found := tree.Lookup(nil)
+	assert.False(t, found)
+	_, deleted := tree.Delete(nil)
+	assert.False(t, deleted)
+
+	_, updated := tree.Insert(Key{}, "empty")
+	assert.False(t, updated)
+	assert.Equal(t, Leaf, tree.root.kind)
+
+	old, updated := tree.Insert(nil, "nil")
+	assert.True(t, updated)
+	assert.Equal(t, "empty", old)
+	assert.Equal(t, 1, tree.Size())
+
+	value, found := tree.Lookup(Key{})
+	assert.True(t, found)
+	assert.Equal(t, "nil", value)
+
+	value, deleted = tree.Delete(nil)
+	assert.True(t, deleted)
+	assert.Equal(t, "nil", value)
+	assert.Nil(t, tree.root)
+
+	tree.Insert(Key("a"), "a")
+	tree.Insert(Key("\x00"), "zero")
+	tree.Insert(nil, "empty")
+	assert.Equal(t, 3, tree.Size())
+
+	key, value, ok := tree.Minimum()
+	assert.True(t, ok)
+	assert.Equal(t, Key{}, key)
+	assert.Equal(t, "empty", value)
+
+	key, _, _ = tree.Maximum()
+	assert.Equal(t, Key("a"), key)
+}
+
+// The empty key must come first in every kind of iteration
+// and be removable from every kind of inner node.
+func TestEmptyKeyForAllNodeTypes(t *testing.T) {
+	for _, n := range []int{1, 4, 5, 16, 17, 48, 49, 256} {
+		tree := newArt[Value]()
+		tree.Insert(Key{}, -1)
+
+		for i := 0; i < n; i++ {
+			tree.Insert(Key{byte(i)}, i)
+		}
+		assert.Equal(t, n+1, tree.Size())
+
+		var keys []Key
+		tree.Each(func(node Node[Value]) {
+			keys = append(keys, node.Key())
+		}, TraverseLeaf)
+		assert.Equal(t, Key{}, keys[0])
+		assert.Len(t, keys, n+1)
+
+		keys = keys[:0]
+		tree.Each(func(node Node[Value]) {
+			keys = append(keys, node.Key())
+		}, TraverseLeaf, TraverseReverse)
+		assert.Equal(t, Key{}, keys[n])
+
+		keys = keys[:0]
+		tree.EachPrefix(Key{}, func(node Node[Value]) {
+			keys = append(keys, node.Key())
+		})
+		assert.Len(t, keys, n+1)
+
+		keys = keys[:0]
+		tree.Range(Key{}, Key{0}, func(node Node[Value]) {
+			keys = append(keys, node.Key())
+		})
+		assert.Equal(t, []Key{{}}, keys)
+
+		keys = keys[:0]
+		tree.Range(Key{}, Key{}, func(node Node[Value]) {
+			keys = append(keys, node.Key())
+		}, RangeIncludeEnd)
+		assert.Equal(t, []Key{{}}, keys)
+
+		keys = keys[:0]
+		tree.Range(nil, Key{}, func(node Node[Value]) {
+			keys = append(keys, node.Key())
+		})
+		assert.Empty(t, keys)
+
+		it := tree.Iterator()
+		assert.True(t, it.Seek(nil))
+		assert.Equal(t, Key{}, it.Key())
+		assert.True(t, it.Next())
+		assert.Equal(t, Key{0}, it.Key())
+		assert.True(t, it.Prev())
+		assert.Equal(t, Key{}, it.Key())
+		assert.False(t, it.Prev())
+
+		key, value, ok := tree.PopMin()
+		assert.True(t, ok)
+		assert.Equal(t, Key{}, key)
+		assert.Equal(t, -1, value)
+		assert.Equal(t, n, tree.Size())
+
+		_, found := tree.Lookup(nil)
+		assert.False(t, found)
+		for i := 0; i < n; i++ {
+			value, found := tree.Lookup(Key{byte(i)})
+			assert.True(t, found)
+			assert.Equal(t, i, value)
+		}
+	}
+}
+
+// Keys which differ only by trailing zero bytes must be stored as distinct keys.
+func TestKeysWithZeroBytesAreDistinct(t *testing.T) {
+	tree := newArt[Value]()
+
+	keys := []string{"a\x00\x00", "a", "a\x00", "a\x00b", "\x00", "b"}
+	for i, key := range keys {
+		_, updated := tree.Insert(Key(key), i)
+		assert.False(t, updated)
+	}
+	assert.Equal(t, len(keys), tree.Size())
+
+	for i, key := range keys {
+		value, found := tree.Lookup(Key(key))
+		assert.True(t, found, key)
+		assert.Equal(t, i, value)
+	}
+
+	_, found := tree.Lookup(Key("a\x00\x00\x00"))
+	assert.False(t, found)
+
+	var actual []string
+	tree.Each(func(node Node[Value]) {
+		actual = append(actual, string(node.Key()))
+	}, TraverseLeaf)
+	assert.Equal(t, []string{"\x00", "a", "a\x00", "a\x00\x00", "a\x00b", "b"}, actual)
+
+	value, deleted := tree.Delete(Key("a\x00"))
+	assert.True(t, deleted)
+	assert.Equal(t, 2, value)
+
+	value, found = tree.Lookup(Key("a"))
+	assert.True(t, found)
+	assert.Equal(t, 1, value)
+
+	_, found = tree.Lookup(Key("a\x00"))
+	assert.False(t, found)
+}
+
+// Keys which are prefixes of other keys must be found, ordered and removed
+// regardless of the order of insertion and removal.
+func TestPrefixKeysInAnyOrder(t *testing.T) {
+	keys := []string{
+		"a", "ab", "abc", "abd", "abcdefghijklmnop", "abcdefghijklmnopq",
+		"abcdefghijklmnopqrstuvwxyz", "abcdefghijklmnopqrstuvwxyz0", "b",
+	}
+	sorted := append([]string(nil), keys...)
+	sort.Strings(sorted)
+
+	rng := rand.New(rand.NewSource(42))
+	for round := 0; round < 100; round++ {
+		tree := newArt[Value]()
+
+		for _, i := range rng.Perm(len(keys)) {
+			tree.Insert(Key(keys[i]), keys[i])
+		}
+		assert.Equal(t, len(keys), tree.Size())
+
+		var actual []string
+		tree.Each(func(node Node[Value]) {
+			actual = append(actual, string(node.Key()))
+		}, TraverseLeaf)
+		assert.Equal(t, sorted, actual)
+
+		actual = actual[:0]
+		for it := tree.Iterator(); it.Prev(); {
+			actual = append(actual, string(it.Key()))
+		}
+		for i := range actual {
+			assert.Equal(t, sorted[len(sorted)-1-i], actual[i])
+		}
+
+		actual = actual[:0]
+		tree.Range(Key("ab"), Key("abcdefghijklmnopq"), func(node Node[Value]) {
+			actual = append(actual, string(node.Key()))
+		}, RangeIncludeEnd)
+		assert.Equal(t, []string{"ab", "abc", "abcdefghijklmnop", "abcdefghijklmnopq"}, actual)
+
+		it := tree.Iterator()
+		assert.True(t, it.Seek(Key("abcdefghijklmnopqr")))
+		assert.Equal(t, Key("abcdefghijklmnopqrstuvwxyz"), it.Key())
+
+		remaining := make(map[string]bool)
+		for _, key := range keys {
+			remaining[key] = true
+		}
+
+		for _, i := range rng.Perm(len(keys)) {
+			value, deleted := tree.Delete(Key(keys[i]))
+			assert.True(t, deleted, keys[i])
+			assert.Equal(t, keys[i], value)
+			delete(remaining, keys[i])
+			assert.Equal(t, len(remaining), tree.Size())
+
+			for _, key := range keys {
+				_, found := tree.Lookup(Key(key))
+				assert.Equal(t, remaining[key], found, key)
+			}
+		}
+		assert.Nil(t, tree.root)
+	}
+}
+
+// DeletePrefix should remove exactly the keys starting with the prefix.
+func TestDeletePrefix(t *testing.T) {
+	tree := newArt[Value]()
+	assert.Zero(t, tree.DeletePrefix(Key("tenant:")))
+
+	keys := []string{
+		"tenant:1234", "tenant:1234:", "tenant:1234:users:1", "tenant:1234:users:2", "tenant:1234:orders:1",
+		"tenant:12345:users:1", "tenant:1235:users:1", "tenant:", "other",
+	}
+	for _, key := range keys {
+		tree.Insert(Key(key), key)
+	}
+
+	assert.Equal(t, 4, tree.DeletePrefix(Key("tenant:1234:")))
+	assert.Equal(t, len(keys)-4, tree.Size())
+	assertLeafCounts(t, tree)
+
+	var actual []string
+	tree.Each(func(node Node[Value]) {
+		actual = append(actual, string(node.Key()))
+	}, TraverseLeaf)
+	assert.Equal(t, []string{"other", "tenant:", "tenant:1234", "tenant:12345:users:1", "tenant:1235:users:1"}, actual)
+
+	assert.Zero(t, tree.DeletePrefix(Key("tenant:1234:")))
+	assert.Zero(t, tree.DeletePrefix(Key("tenant:9")))
+	assert.Zero(t, tree.DeletePrefix(Key("tenant:12345:users:10")))
+
+	// The prefix ends within a compressed path.
+	assert.Equal(t, 1, tree.DeletePrefix(Key("tenant:12345:us")))
+	assert.Equal(t, 2, tree.DeletePrefix(Key("tenant:123")))
+	assert.Equal(t, 1, tree.DeletePrefix(Key("tenant:")))
+	assert.Equal(t, Leaf, tree.root.kind)
+	assert.Equal(t, 1, tree.Size())
+
+	assert.Equal(t, 1, tree.DeletePrefix(nil))
+	assert.Nil(t, tree.root)
+	assert.Zero(t, tree.Size())
+}
+
+// DeletePrefix should keep the tree consistent for all kinds of nodes.
+func TestDeletePrefixForAllNodeTypes(t *testing.T) {
+	rng := rand.New(rand.NewSource(29))
+
+	for round := 0; round < 50; round++ {
+		tree := newArt[Value]()
+		present := make(map[string]bool)
+		for i := 0; i < 1000; i++ {
+			key := make(Key, rng.Intn(4))
+			for j := range key {
+				key[j] = byte(rng.Intn(8) * 9)
+			}
+			tree.Insert(key, nil)
+			present[string(key)] = true
+		}
+
+		for len(present) > 0 {
+			prefix := make(Key, rng.Intn(4))
+			for j := range prefix {
+				prefix[j] = byte(rng.Intn(8) * 9)
+			}
+
+			expected := 0
+			for key := range present {
+				if bytes.HasPrefix(Key(key), prefix) {
+					expected++
+					delete(present, key)
+				}
+			}
+
+			assert.Equal(t, expected, tree.DeletePrefix(prefix))
+			assert.Equal(t, len(present), tree.Size())
+		}
+		assert.Nil(t, tree.root)
+	}
+
+	tree := newArt[Value]()
+	for i := 0; i < 256; i++ {
+		tree.Insert(Key{byte(i), 1}, nil)
+		tree.Insert(Key{byte(i), 2}, nil)
+	}
+	for i := 0; i < 256; i++ {
+		assert.Equal(t, 2, tree.DeletePrefix(Key{byte(i)}))
+		assert.Equal(t, 2*(255-i), tree.Size())
+		assertLeafCounts(t, tree)
+	}
+}
+
+// DeleteRange should remove exactly the keys within the range.
+func TestDeleteRange(t *testing.T) {
+	tree := newArt[Value]()
+	assert.Zero(t, tree.DeleteRange(nil, nil))
+
+	keys := []string{
+		"events:2024-01-01", "events:2024-01-02", "events:2024-02-01", "events:2024-02-01:late",
+		"events:2024-03-01", "events:", "users:1",
+	}
+	for _, key := range keys {
+		tree.Insert(Key(key), key)
+	}
+
+	assert.Equal(t, 3, tree.DeleteRange(Key("events:2024"), Key("events:2024-02-01:")))
+	assert.Equal(t, len(keys)-3, tree.Size())
+	assertLeafCounts(t, tree)
+
+	var actual []string
+	tree.Each(func(node Node[Value]) {
+		actual = append(actual, string(node.Key()))
+	}, TraverseLeaf)
+	assert.Equal(t, []string{"events:", "events:2024-02-01:late", "events:2024-03-01", "users:1"}, actual)
+
+	assert.Zero(t, tree.DeleteRange(Key("events:2024"), Key("events:2024-02-01:")))
+	assert.Zero(t, tree.DeleteRange(Key("users:2"), Key("users:1")))
+	assert.Equal(t, 1, tree.DeleteRange(Key("events:2024-03-01"), Key("users:1"), RangeExcludeStart, RangeIncludeEnd))
+	assert.Equal(t, 2, tree.DeleteRange(nil, Key("events:2024-03")))
+	assert.Equal(t, Leaf, tree.root.kind)
+	assert.Equal(t, 1, tree.Size())
+
+	assert.Equal(t, 1, tree.DeleteRange(nil, nil))
+	assert.Nil(t, tree.root)
+	assert.Zero(t, tree.Size())
+}
+
+// DeleteRange should keep the tree consistent for all kinds of nodes.
+func TestDeleteRangeForAllNodeTypes(t *testing.T) {
+	rng := rand.New(rand.NewSource(31))
+	randomKey := func() Key {
+		key := make(Key, rng.Intn(4))
+		for j := range key {
+			key[j] = byte(rng.Intn(64) * 4)
+		}
+		return key
+	}
+
+	for round := 0; round < 50; round++ {
+		tree := newArt[Value]()
+		present := make(map[string]bool)
+		for i := 0; i < 2000; i++ {
+			key := randomKey()
+			tree.Insert(key, nil)
+			present[string(key)] = true
+		}
+
+		for len(present) > 0 {
+			start, end := randomKey(), randomKey()
+			if rng.Intn(4) == 0 {
+				start = nil
+			}
+			if rng.Intn(4) == 0 {
+				end = nil
+			}
+			excludeStart, includeEnd := rng.Intn(2) == 0, rng.Intn(2) == 0
+			var options []int
+			if excludeStart {
+				options = append(options, RangeExcludeStart)
+			}
+			if includeEnd {
+				options = append(options, RangeIncludeEnd)
+			}
+
+			var expected []string
+			for key := range present {
+				if start != nil {
+					if c := bytes.Compare(Key(key), start); c < 0 || c == 0 && excludeStart {
+						continue
+					}
+				}
+				if end != nil {
+					if c := bytes.Compare(Key(key), end); c > 0 || c == 0 && !includeEnd {
+						continue
+					}
+				}
+				expected = append(expected, key)
+			}
+
+			assert.Equal(t, len(expected), tree.Count(start, end, options...))
+			assert.Equal(t, len(expected), tree.DeleteRange(start, end, options...))
+			for _, key := range expected {
+				delete(present, key)
+			}
+			assert.Equal(t, len(present), tree.Size())
+			assertLeafCounts(t, tree)
+
+			remaining := 0
+			tree.Each(func(node Node[Value]) {
+				assert.True(t, present[string(node.Key())])
+				remaining++
+			}, TraverseLeaf)
+			assert.Equal(t, len(present), remaining)
+		}
+		assert.Nil(t, tree.root)
+	}
+}
+
 // Inserting Two values into the tree and removing one of them
 // should result in a tree root of type Leaf
 func TestInsert2AndRemove1AndRootShouldBeLeafNode(t *testing.T) {
-	tree := newArt()
+	tree := newArt[Value]()
 
 	tree.Insert(Key("test"), []byte("data"))
 	tree.Insert(Key("test2"), []byte("data"))
@@ -192,7 +1374,7 @@ func TestInsert2AndRemove1AndRootShouldBeLeafNode(t *testing.T) {
 // This tests the expansion of the root into a Node4 and
 // successfully collapsing into a Leaf and then nil upon successive removals
 func TestInsert2AndRemove2AndRootShouldBeNil(t *testing.T) {
-	tree := newArt()
+	tree := newArt[Value]()
 
 	tree.Insert(Key("test"), []byte("data"))
 	tree.Insert(Key("test2"), []byte("data"))
@@ -209,7 +1391,7 @@ func TestInsert2AndRemove2AndRootShouldBeNil(t *testing.T) {
 // This tests the expansion of the root into a Node16 and
 // successfully collapsing into a Node4 upon successive removals
 func TestInsert5AndRemove1AndRootShouldBeNode4(t *testing.T) {
-	tree := newArt()
+	tree := newArt[Value]()
 
 	for i := 0; i < 5; i++ {
 		tree.Insert(Key{byte(i)}, []byte{byte(i)})
@@ -229,7 +1411,7 @@ func TestInsert5AndRemove1AndRootShouldBeNode4(t *testing.T) {
 // This tests the expansion of the root into a Node16 and
 // successfully collapsing into a Node4, Leaf, then nil
 func TestInsert5AndRemove5AndRootShouldBeNil(t *testing.T) {
-	tree := newArt()
+	tree := newArt[Value]()
 
 	for i := 0; i < 5; i++ {
 		tree.Insert(Key{byte(i)}, []byte{byte(i)})
@@ -254,7 +1436,7 @@ func TestInsert5AndRemove5AndRootShouldBeNil(t *testing.T) {
 // This tests the expansion of the root into a Node48, and
 // successfully collapsing into a Node16
 func TestInsert17AndRemove1AndRootShouldBeNode16(t *testing.T) {
-	tree := newArt()
+	tree := newArt[Value]()
 
 	for i := 0; i < 17; i++ {
 		tree.Insert(Key{byte(i)}, []byte{byte(i)})
@@ -274,7 +1456,7 @@ func TestInsert17AndRemove1AndRootShouldBeNode16(t *testing.T) {
 // This tests the expansion of the root into a Node48, and
 // successfully collapsing into a Node16, Node4, Leaf, and then nil
 func TestInsert17AndRemove17AndRootShouldBeNil(t *testing.T) {
-	tree := newArt()
+	tree := newArt[Value]()
 
 	for i := 0; i < 17; i++ {
 		tree.Insert(Key{byte(i)}, []byte{byte(i)})
@@ -298,7 +1480,7 @@ func TestInsert17AndRemove17AndRootShouldBeNil(t *testing.T) {
 // This tests the expansion of the root into a Node256, and
 // successfully collapasing into a Node48
 func TestInsert49AndRemove1AndRootShouldBeNode48(t *testing.T) {
-	tree := newArt()
+	tree := newArt[Value]()
 
 	for i := 0; i < 49; i++ {
 		tree.Insert(Key{byte(i)}, []byte{byte(i)})
@@ -319,7 +1501,7 @@ func TestInsert49AndRemove1AndRootShouldBeNode48(t *testing.T) {
 // This tests the expansion of the root into a Node256, and
 // successfully collapsing into a Node48, Node16, Node4, Leaf, and finally nil
 func TestInsert49AndRemove49AndRootShouldBeNil(t *testing.T) {
-	tree := newArt()
+	tree := newArt[Value]()
 
 	for i := 0; i < 49; i++ {
 		tree.Insert(Key{byte(i)}, []byte{byte(i)})
@@ -339,13 +1521,13 @@ func TestInsert49AndRemove49AndRootShouldBeNil(t *testing.T) {
 
 // A traversal of the tree should be in preorder
 func TestEachPreOrderness(t *testing.T) {
-	tree := newArt()
+	tree := newArt[Value]()
 	tree.Insert(Key("1"), []byte("1"))
 	tree.Insert(Key("2"), []byte("2"))
 
-	var traversal []Node
+	var traversal []Node[Value]
 
-	tree.Each(func(node Node) {
+	tree.Each(func(node Node[Value]) {
 		traversal = append(traversal, node)
 	})
 
@@ -365,15 +1547,15 @@ func TestEachPreOrderness(t *testing.T) {
 // Node48s do not store their children in order, and require different logic to traverse them
 // so we must test that logic seperately.
 func TestEachNode48(t *testing.T) {
-	tree := newArt()
+	tree := newArt[Value]()
 
 	for i := 48; i > 0; i-- {
 		tree.Insert(Key{byte(i)}, []byte{byte(i)})
 	}
 
-	var traversal []Node
+	var traversal []Node[Value]
 
-	tree.Each(func(node Node) {
+	tree.Each(func(node Node[Value]) {
 		traversal = append(traversal, node)
 	})
 
@@ -390,7 +1572,7 @@ func TestEachNode48(t *testing.T) {
 // After inserting many values into the tree, we should be able to iterate through all of them
 // And get the expected number of nodes.
 func TestEachFullIterationExpectCountOfAllTypes(t *testing.T) {
-	tree := newArt()
+	tree := newArt[Value]()
 
 	words := test.LoadTestFile("test/data/words.txt")
 
@@ -404,7 +1586,7 @@ func TestEachFullIterationExpectCountOfAllTypes(t *testing.T) {
 	var node48Count int = 0
 	var node256Count int = 0
 
-	tree.Each(func(node Node) {
+	tree.Each(func(node Node[Value]) {
 		switch node.Kind() {
 		case Node4:
 			node4Count++
@@ -421,16 +1603,459 @@ func TestEachFullIterationExpectCountOfAllTypes(t *testing.T) {
 	})
 
 	assert.Equalf(t, 235886, leafCount, "leaf count must be equal to 235886")
-	assert.Equalf(t, 111616, node4Count, "node4 count must be equal to 111616")
-	assert.Equalf(t, 12181, node16Count, "node16 count must be equal to 12181")
-	assert.Equalf(t, 458, node48Count, "node48 count must be equal to 458")
+	assert.Equalf(t, 113419, node4Count, "node4 count must be equal to 113419")
+	assert.Equalf(t, 10433, node16Count, "node16 count must be equal to 10433")
+	assert.Equalf(t, 403, node48Count, "node48 count must be equal to 403")
 	assert.Equalf(t, 1, node256Count, "node256 must be the only one")
 }
 
+// Prefix iteration should visit only the leaves with the given prefix in key order.
+func TestEachPrefix(t *testing.T) {
+	tree := newArt[Value]()
+
+	for _, w := range []string{"app", "api", "apple", "application", "apply", "banana"} {
+		tree.Insert(Key(w), w)
+	}
+
+	var keys []string
+	tree.EachPrefix(Key("app"), func(node Node[Value]) {
+		assert.Equal(t, Leaf, node.Kind())
+		keys = append(keys, string(node.Key()))
+	})
+	assert.Equal(t, []string{"app", "apple", "application", "apply"}, keys)
+
+	keys = nil
+	tree.EachPrefix(Key("appl"), func(node Node[Value]) {
+		keys = append(keys, string(node.Key()))
+	})
+	assert.Equal(t, []string{"apple", "application", "apply"}, keys)
+
+	keys = nil
+	tree.EachPrefix(Key("b"), func(node Node[Value]) {
+		keys = append(keys, string(node.Key()))
+	})
+	assert.Equal(t, []string{"banana"}, keys)
+
+	keys = nil
+	tree.EachPrefix(Key("c"), func(node Node[Value]) {
+		keys = append(keys, string(node.Key()))
+	})
+	assert.Empty(t, keys)
+
+	keys = nil
+	tree.EachPrefix(Key("bananas"), func(node Node[Value]) {
+		keys = append(keys, string(node.Key()))
+	})
+	assert.Empty(t, keys)
+
+	keys = nil
+	tree.EachPrefix(nil, func(node Node[Value]) {
+		keys = append(keys, string(node.Key()))
+	})
+	assert.Len(t, keys, 6)
+}
+
+// Prefix iteration should work when the prefix ends inside a compressed path
+// that is longer than the stored part of the prefix.
+func TestEachPrefixWithinLongCompressedPath(t *testing.T) {
+	tree := newArt[Value]()
+
+	tree.Insert(Key("tenant:0123456789:user:1"), 1)
+	tree.Insert(Key("tenant:0123456789:user:2"), 2)
+	tree.Insert(Key("tenant:0123456789:group:1"), 3)
+
+	for _, prefix := range []string{"ten", "tenant:01234", "tenant:0123456789:"} {
+		count := 0
+		tree.EachPrefix(Key(prefix), func(node Node[Value]) {
+			count++
+		})
+		assert.Equal(t, 3, count, prefix)
+	}
+
+	count := 0
+	tree.EachPrefix(Key("tenant:0123456789:user:"), func(node Node[Value]) {
+		count++
+	})
+	assert.Equal(t, 2, count)
+
+	count = 0
+	tree.EachPrefix(Key("tenant:0123456780"), func(node Node[Value]) {
+		count++
+	})
+	assert.Zero(t, count)
+}
+
+// Prefix iteration over the dictionary should find the same words as a full scan.
+func TestEachPrefixManyWords(t *testing.T) {
+	tree := newArt[Value]()
+
+	words := test.LoadTestFile("test/data/words.txt")
+	for _, w := range words {
+		tree.Insert(w, w)
+	}
+
+	for _, prefix := range []string{"a", "re", "inter", "zy", "Q", "qwerty"} {
+		var expected []string
+		for _, w := range words {
+			if bytes.HasPrefix(w, Key(prefix)) {
+				expected = append(expected, string(w))
+			}
+		}
+		sort.Strings(expected)
+
+		var keys []string
+		tree.EachPrefix(Key(prefix), func(node Node[Value]) {
+			keys = append(keys, string(node.Key()))
+		})
+		assert.Equal(t, expected, keys, prefix)
+	}
+}
+
+// Range iteration should respect the default bounds, the bound options and open bounds.
+func TestRange(t *testing.T) {
+	tree := newArt[Value]()
+
+	for _, w := range []string{"a", "b", "ba", "bb", "c", "ca", "d"} {
+		tree.Insert(Key(w), w)
+	}
+
+	collect := func(start, end Key, options ...int) []string {
+		var keys []string
+		tree.Range(start, end, func(node Node[Value]) {
+			assert.Equal(t, Leaf, node.Kind())
+			keys = append(keys, string(node.Key()))
+		}, options...)
+		return keys
+	}
+
+	assert.Equal(t, []string{"b", "ba", "bb"}, collect(Key("b"), Key("c")))
+	assert.Equal(t, []string{"ba", "bb"}, collect(Key("b"), Key("c"), RangeExcludeStart))
+	assert.Equal(t, []string{"b", "ba", "bb", "c"}, collect(Key("b"), Key("c"), RangeIncludeEnd))
+	assert.Equal(t, []string{"ba", "bb", "c"}, collect(Key("b"), Key("c"), RangeExcludeStart|RangeIncludeEnd))
+	assert.Equal(t, []string{"a", "b", "ba"}, collect(nil, Key("bb")))
+	assert.Equal(t, []string{"ca", "d"}, collect(Key("c0"), nil))
+	assert.Equal(t, []string{"a", "b", "ba", "bb", "c", "ca", "d"}, collect(nil, nil))
+	assert.Equal(t, []string{"bb", "c"}, collect(Key("bab"), Key("c0")))
+	assert.Empty(t, collect(Key("c"), Key("b")))
+	assert.Empty(t, collect(Key("e"), nil))
+}
+
+// Range iteration should prune children of all inner node types.
+func TestRangeForAllNodeTypes(t *testing.T) {
+	for _, total := range []int{4, 16, 48, 256} {
+		tree := newArt[Value]()
+		for i := 0; i < total; i++ {
+			tree.Insert(Key{byte(i), 'x'}, i)
+		}
+
+		var values []int
+		tree.Range(Key{1, 'x'}, Key{byte(total - 1)}, func(node Node[Value]) {
+			values = append(values, node.Value().(int))
+		})
+
+		assert.Len(t, values, total-2)
+		for i, value := range values {
+			assert.Equal(t, i+1, value)
+		}
+	}
+}
+
+// Range iteration over the dictionary should find the same words as a full scan.
+func TestRangeManyWords(t *testing.T) {
+	tree := newArt[Value]()
+
+	words := test.LoadTestFile("test/data/words.txt")
+	for _, w := range words {
+		tree.Insert(w, w)
+	}
+
+	bounds := []struct {
+		start, end Key
+		options    int
+	}{
+		{Key("apple"), Key("apricot"), 0},
+		{Key("apple"), Key("apricot"), RangeExcludeStart | RangeIncludeEnd},
+		{Key("interstellar"), Key("intervene"), RangeIncludeEnd},
+		{Key("Z"), Key("b"), 0},
+		{nil, Key("Ab"), 0},
+		{Key("zym"), nil, 0},
+	}
+
+	for _, b := range bounds {
+		var expected []string
+		for _, w := range words {
+			if b.start != nil {
+				if c := bytes.Compare(w, b.start); c < 0 || c == 0 && b.options&RangeExcludeStart != 0 {
+					continue
+				}
+			}
+			if b.end != nil {
+				if c := bytes.Compare(w, b.end); c > 0 || c == 0 && b.options&RangeIncludeEnd == 0 {
+					continue
+				}
+			}
+			expected = append(expected, string(w))
+		}
+		sort.Strings(expected)
+
+		var keys []string
+		tree.Range(b.start, b.end, func(node Node[Value]) {
+			keys = append(keys, string(node.Key()))
+		}, b.options)
+		assert.Equal(t, expected, keys, "%s - %s", b.start, b.end)
+	}
+}
+
+// A reverse traversal should visit the leaves of all node types in descending order.
+func TestEachReverse(t *testing.T) {
+	for _, total := range []int{4, 16, 48, 256} {
+		tree := newArt[Value]()
+		for i := 0; i < total; i++ {
+			tree.Insert(Key{byte(i)}, i)
+		}
+
+		var traversal []Node[Value]
+		tree.Each(func(node Node[Value]) {
+			traversal = append(traversal, node)
+		}, TraverseReverse)
+
+		assert.Equal(t, tree.root, traversal[0])
+		assert.Len(t, traversal, total+1)
+		for i, node := range traversal[1:] {
+			assert.Equal(t, Key{byte(total - 1 - i)}, node.Key())
+		}
+	}
+}
+
+// Reverse traversals of the dictionary should return the words in descending order.
+func TestReverseManyWords(t *testing.T) {
+	tree := newArt[Value]()
+
+	words := test.LoadTestFile("test/data/words.txt")
+	for _, w := range words {
+		tree.Insert(w, w)
+	}
+
+	var forward, backward []string
+	tree.Each(func(node Node[Value]) {
+		if node.Kind() == Leaf {
+			forward = append(forward, string(node.Key()))
+		}
+	})
+	tree.Each(func(node Node[Value]) {
+		if node.Kind() == Leaf {
+			backward = append(backward, string(node.Key()))
+		}
+	}, TraverseReverse)
+
+	assert.Len(t, backward, len(words))
+	assert.True(t, sort.StringsAreSorted(forward))
+	for i := range backward {
+		assert.Equal(t, forward[len(forward)-1-i], backward[i])
+	}
+
+	forward, backward = nil, nil
+	tree.EachPrefix(Key("inter"), func(node Node[Value]) {
+		forward = append(forward, string(node.Key()))
+	})
+	tree.EachPrefix(Key("inter"), func(node Node[Value]) {
+		backward = append(backward, string(node.Key()))
+	}, TraverseReverse)
+
+	assert.NotEmpty(t, backward)
+	assert.Equal(t, len(forward), len(backward))
+	for i := range backward {
+		assert.Equal(t, forward[len(forward)-1-i], backward[i])
+	}
+
+	forward, backward = nil, nil
+	tree.Range(Key("apple"), Key("apricot"), func(node Node[Value]) {
+		forward = append(forward, string(node.Key()))
+	}, RangeIncludeEnd)
+	tree.Range(Key("apple"), Key("apricot"), func(node Node[Value]) {
+		backward = append(backward, string(node.Key()))
+	}, RangeIncludeEnd|TraverseReverse)
+
+	assert.NotEmpty(t, backward)
+	assert.Equal(t, "apricot", backward[0])
+	assert.Equal(t, "apple", backward[len(backward)-1])
+	assert.Equal(t, len(forward), len(backward))
+	for i := range backward {
+		assert.Equal(t, forward[len(forward)-1-i], backward[i])
+	}
+}
+
+// Walk should stop as soon as the callback returns SkipAll.
+func TestWalkSkipAll(t *testing.T) {
+	tree := newArt[Value]()
+
+	words := test.LoadTestFile("test/data/words.txt")
+	for _, w := range words {
+		tree.Insert(w, w)
+	}
+
+	for _, opts := range []int{0, TraverseReverse} {
+		var leaves []Node[Value]
+		err := tree.Walk(func(node Node[Value]) error {
+			if node.Kind() == Leaf {
+				leaves = append(leaves, node)
+				if len(leaves) == 10 {
+					return SkipAll
+				}
+			}
+			return nil
+		}, opts)
+
+		assert.NoError(t, err)
+		assert.Len(t, leaves, 10)
+	}
+}
+
+// Walk should not descend into a node for which the callback returns SkipNode,
+// for every kind of node.
+func TestWalkSkipNode(t *testing.T) {
+	kinds := map[int]Kind{4: Node4, 16: Node16, 48: Node48, 256: Node256}
+
+	for total, kind := range kinds {
+		tree := newArt[Value]()
+		for i := 0; i < total; i++ {
+			tree.Insert(Key{'a', byte(i)}, i)
+			tree.Insert(Key{'b', byte(i)}, i)
+		}
+
+		var leaves []Key
+		err := tree.Walk(func(node Node[Value]) error {
+			if node.Kind() == Leaf {
+				leaves = append(leaves, node.Key())
+				return nil
+			}
+			// Skip the subtree of keys that start with 'a'.
+			if node.(*artNode[Value]).maximum().Key()[0] == 'a' {
+				assert.Equal(t, kind, node.Kind())
+				return SkipNode
+			}
+			return nil
+		})
+
+		assert.NoError(t, err)
+		assert.Len(t, leaves, total)
+		for _, key := range leaves {
+			assert.Equal(t, byte('b'), key[0])
+		}
+	}
+}
+
+// Walk should stop and return the error returned by the callback.
+func TestWalkReturnsError(t *testing.T) {
+	tree := newArt[Value]()
+	for _, w := range []string{"a", "b", "c"} {
+		tree.Insert(Key(w), w)
+	}
+
+	stop := errors.New("stop")
+	var visited []string
+	err := tree.Walk(func(node Node[Value]) error {
+		if node.Kind() != Leaf {
+			return nil
+		}
+		visited = append(visited, string(node.Key()))
+		if string(node.Key()) == "b" {
+			return stop
+		}
+		// Skipping the children of a leaf changes nothing.
+		return SkipNode
+	})
+
+	assert.Equal(t, stop, err)
+	assert.Equal(t, []string{"a", "b"}, visited)
+}
+
+// Traversal options should choose the kinds of nodes to visit.
+func TestEachKindOptions(t *testing.T) {
+	tree := newArt[Value]()
+
+	words := test.LoadTestFile("test/data/words.txt")
+	for _, w := range words {
+		tree.Insert(Key(w), []byte(w))
+	}
+
+	count := func(options ...int) map[Kind]int {
+		kinds := make(map[Kind]int)
+		tree.Each(func(node Node[Value]) {
+			kinds[node.Kind()]++
+		}, options...)
+		return kinds
+	}
+
+	assert.Equal(t, map[Kind]int{Leaf: 235886}, count(TraverseLeaf))
+	assert.Equal(t, map[Kind]int{Node4: 113419, Node16: 10433, Node48: 403, Node256: 1}, count(TraverseNode))
+	assert.Equal(t, map[Kind]int{Node48: 403, Node256: 1}, count(TraverseNode48|TraverseNode256))
+	assert.Equal(t, map[Kind]int{Leaf: 235886, Node16: 10433}, count(TraverseLeaf|TraverseNode16, TraverseReverse))
+	assert.Equal(t, count(), count(TraverseAll))
+}
+
+// A post-order traversal should visit inner nodes after their children.
+func TestEachPostOrderness(t *testing.T) {
+	tree := newArt[Value]()
+	tree.Insert(Key("1"), []byte("1"))
+	tree.Insert(Key("2"), []byte("2"))
+
+	var traversal []Node[Value]
+	tree.Each(func(node Node[Value]) {
+		traversal = append(traversal, node)
+	}, TraversePostOrder)
+
+	// Order should be 1, 2, Node4
+	assert.Len(t, traversal, 3)
+	assert.Equal(t, Key("1"), traversal[0].Key())
+	assert.Equal(t, Key("2"), traversal[1].Key())
+	assert.Equal(t, tree.root, traversal[2])
+
+	traversal = nil
+	tree.Each(func(node Node[Value]) {
+		traversal = append(traversal, node)
+	}, TraversePostOrder|TraverseReverse)
+
+	// Order should be 2, 1, Node4
+	assert.Equal(t, Key("2"), traversal[0].Key())
+	assert.Equal(t, Key("1"), traversal[1].Key())
+	assert.Equal(t, tree.root, traversal[2])
+}
+
+// A post-order walk should still stop on SkipAll and ignore SkipNode.
+func TestWalkPostOrder(t *testing.T) {
+	tree := newArt[Value]()
+	for _, w := range []string{"aa", "ab", "ba", "bb"} {
+		tree.Insert(Key(w), w)
+	}
+
+	var visited []Node[Value]
+	err := tree.Walk(func(node Node[Value]) error {
+		visited = append(visited, node)
+		if node.Kind() != Leaf {
+			return SkipNode
+		}
+		return nil
+	}, TraversePostOrder)
+	assert.NoError(t, err)
+	assert.Len(t, visited, 7)
+	assert.Equal(t, tree.root, visited[6])
+
+	visited = nil
+	err = tree.Walk(func(node Node[Value]) error {
+		visited = append(visited, node)
+		return SkipAll
+	}, TraversePostOrder|TraverseNode)
+	assert.NoError(t, err)
+	assert.Len(t, visited, 1)
+	assert.Equal(t, Node4, visited[0].Kind())
+	assert.NotEqual(t, tree.root, visited[0])
+}
+
 // After Inserting many values into the tree, we should be able to remove them all
 // And expect nothing to exist in the tree.
 func TestInsertManyWordsAndRemoveThemAll(t *testing.T) {
-	tree := newArt()
+	tree := newArt[Value]()
 
 	words := test.LoadTestFile("test/data/words.txt")
 
@@ -455,7 +2080,7 @@ func TestInsertManyWordsAndRemoveThemAll(t *testing.T) {
 // After Inserting many values into the tree, we should be able to remove them all
 // And expect nothing to exist in the tree.
 func TestInsertManyUUIDsAndRemoveThemAll(t *testing.T) {
-	tree := newArt()
+	tree := newArt[Value]()
 
 	uuids := test.LoadTestFile("test/data/uuid.txt")
 
@@ -481,7 +2106,7 @@ func TestInsertManyUUIDsAndRemoveThemAll(t *testing.T) {
 func TestInsertWithSameByteSliceAddress(t *testing.T) {
 	rand.Seed(42)
 	key := make([]byte, 8)
-	tree := newArt()
+	tree := newArt[Value]()
 
 	// Keep track of what we inserted
 	keys := make(map[string]bool)
@@ -509,7 +2134,7 @@ func BenchmarkWordsTreeInsert(b *testing.B) {
 	words := test.LoadTestFile("test/data/words.txt")
 	b.ResetTimer()
 	for n := 0; n < b.N; n++ {
-		tree := newArt()
+		tree := newArt[Value]()
 		for _, w := range words {
 			tree.Insert(w, w)
 		}
@@ -518,7 +2143,7 @@ func BenchmarkWordsTreeInsert(b *testing.B) {
 
 func BenchmarkWordsTreeSearch(b *testing.B) {
 	words := test.LoadTestFile("test/data/words.txt")
-	tree := newArt()
+	tree := newArt[Value]()
 	for _, w := range words {
 		tree.Insert(w, w)
 	}
@@ -532,24 +2157,24 @@ func BenchmarkWordsTreeSearch(b *testing.B) {
 
 func BenchmarkWordsTreeForEach(b *testing.B) {
 	words := test.LoadTestFile("test/data/words.txt")
-	tree := newArt()
+	tree := newArt[Value]()
 	for _, w := range words {
 		tree.Insert(w, w)
 	}
 	b.ResetTimer()
 
 	nodeTypes := make(map[Kind]int)
-	tree.Each(func(n Node) {
+	tree.Each(func(n Node[Value]) {
 		nodeTypes[n.Kind()]++
 	})
-	assert.Equal(b, map[Kind]int{Leaf: 235886, Node4: 111616, Node16: 12181, Node48: 458, Node256: 1}, nodeTypes)
+	assert.Equal(b, map[Kind]int{Leaf: 235886, Node4: 113419, Node16: 10433, Node48: 403, Node256: 1}, nodeTypes)
 }
 
 func BenchmarkUUIDsTreeInsert(b *testing.B) {
 	words := test.LoadTestFile("test/data/uuid.txt")
 	b.ResetTimer()
 	for n := 0; n < b.N; n++ {
-		tree := newArt()
+		tree := newArt[Value]()
 		for _, w := range words {
 			tree.Insert(w, w)
 		}
@@ -558,7 +2183,7 @@ func BenchmarkUUIDsTreeInsert(b *testing.B) {
 
 func BenchmarkUUIDsTreeSearch(b *testing.B) {
 	words := test.LoadTestFile("test/data/uuid.txt")
-	tree := newArt()
+	tree := newArt[Value]()
 	for _, w := range words {
 		tree.Insert(w, w)
 	}
@@ -572,14 +2197,14 @@ func BenchmarkUUIDsTreeSearch(b *testing.B) {
 
 func BenchmarkUUIDsTreeEach(b *testing.B) {
 	words := test.LoadTestFile("test/data/uuid.txt")
-	tree := newArt()
+	tree := newArt[Value]()
 	for _, w := range words {
 		tree.Insert(w, w)
 	}
 	b.ResetTimer()
 
 	nodeTypes := make(map[Kind]int)
-	tree.Each(func(n Node) {
+	tree.Each(func(n Node[Value]) {
 		nodeTypes[n.Kind()]++
 	})
 	assert.Equal(b, map[Kind]int{Leaf: 500000, Node4: 103602, Node16: 56030}, nodeTypes)